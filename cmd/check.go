@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that signals hold expected values in a VCD file",
+	Long: `Checks that signals in a VCD (Value Change Dump) file hold the expected
+values at the given times, printing every discrepancy. The assertions file
+holds "signal,time,expected" records, one per line. The command exits with
+a non-zero status if any assertion fails.
+
+Example:
+go-vcd2svg check -i input.vcd -a assertions.csv`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := cmd.Flags().Lookup("input").Value.String()
+		assertionsFile := cmd.Flags().Lookup("assertions").Value.String()
+
+		// check if the input exists
+		if !fileExists(input) {
+			fmt.Println("File does not exist:", input)
+			os.Exit(1)
+		}
+
+		assertions, err := waveform.LoadAssertions(assertionsFile)
+		if err != nil {
+			fmt.Printf("Error reading assertions: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		vcdData, err := waveform.ParseVCDFile(input)
+		if err != nil {
+			fmt.Printf("Error parsing VCD: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		mismatches := vcdData.Check(assertions)
+		for _, mismatch := range mismatches {
+			fmt.Fprintln(cmd.OutOrStdout(), mismatch)
+		}
+		if len(mismatches) > 0 {
+			return fmt.Errorf("%d of %d assertions failed", len(mismatches), len(assertions))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().StringP("input", "i", "", "Input VCD file path")
+	checkCmd.Flags().StringP("assertions", "a", "", "CSV file of signal,time,expected records")
+	checkCmd.MarkFlagRequired("input")
+	checkCmd.MarkFlagRequired("assertions")
+}
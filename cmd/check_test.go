@@ -0,0 +1,52 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCmd(t *testing.T) {
+	dir := t.TempDir()
+	passing := filepath.Join(dir, "passing.csv")
+	failing := filepath.Join(dir, "failing.csv")
+	if err := os.WriteFile(passing, []byte("clk,2,1\ncounter,3,010\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(failing, []byte("clk,2,1\ncounter,3,111\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+
+	rootCmd.SetArgs([]string{"check", "-i", blinkyVcd, "-a", passing})
+	assert.NoError(t, rootCmd.Execute())
+	assert.NotContains(t, out.String(), "expected")
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"check", "-i", blinkyVcd, "-a", failing})
+	assert.EqualError(t, rootCmd.Execute(), "1 of 2 assertions failed")
+	assert.Contains(t, out.String(), "counter at 3: expected 111, got 010")
+}
@@ -16,10 +16,17 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/titan098/go-vcd2svg/waveform"
 )
 
@@ -29,15 +36,18 @@ var convertCmd = &cobra.Command{
 	Short: "Convert a VCD (Value Change Dump) file to an SVG",
 	Long: `Converts a VCD (Value Change Dump) file to an SVG diagram.
 	
+Pass "-" as the input to read the VCD from standard input.
+
 Example:
-go-vcd2svg convert -i input.vcd -o output.svg`,
+go-vcd2svg convert -i input.vcd -o output.svg
+simulate | go-vcd2svg convert -i - -o output.svg`,
 	Run: func(cmd *cobra.Command, args []string) {
 		input := cmd.Flags().Lookup("input").Value.String()
 		output := cmd.Flags().Lookup("output").Value.String()
 
-		// check if the input exists
-		if !fileExists(input) {
-			fmt.Println("File does not exist:", args[0])
+		// check if the input exists, unless it is read from stdin
+		if input != "-" && !fileExists(input) {
+			fmt.Println("File does not exist:", input)
 			os.Exit(1)
 		}
 
@@ -46,27 +56,116 @@ go-vcd2svg convert -i input.vcd -o output.svg`,
 			fmt.Println("File already exists:", output)
 			os.Exit(1)
 		}
+		thumbnail, _ := cmd.Flags().GetString("thumbnail")
+		if thumbnail != "" && fileExists(thumbnail) {
+			fmt.Println("File already exists:", thumbnail)
+			os.Exit(1)
+		}
+
+		// check that the output directories exist, creating them if requested
+		createDirs, _ := cmd.Flags().GetBool("create-dirs")
+		for _, path := range []string{output, thumbnail} {
+			if path == "" || path == "-" {
+				continue
+			}
+			if err := ensureOutputDir(path, createDirs); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		}
 
-		// generate the SVG
-		outBytes, err := waveform.SvgFromFile(input)
+		// collect the render options from the flags
+		opts, err := renderOptions(cmd)
 		if err != nil {
-			fmt.Printf("Error generating SVG: %s\n", err.Error())
+			fmt.Printf("Error reading render options: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		opts.PNGScale, _ = cmd.Flags().GetFloat64("png-scale")
+
+		// generate the output in the requested format
+		format := outputFormat(cmd, output)
+		vcdData, err := readInput(cmd.InOrStdin(), input)
+		if err != nil {
+			fmt.Printf("Error reading input: %s\n", err.Error())
+			os.Exit(1)
+		}
+		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Parsed", vcdData.Stats())
+		}
+		if signals, _ := cmd.Flags().GetStringSlice("signals"); len(signals) > 0 {
+			vcdData = vcdData.Filter(signals)
+		}
+		outBytes, err := waveform.RenderFormat(format, vcdData, opts)
+		if err != nil {
+			fmt.Printf("Error generating output: %s\n", err.Error())
+			os.Exit(1)
 		}
 
 		// write the file to the specified file
-		if output != ""  && output != "-" {
+		if output != "" && output != "-" {
 			err := os.WriteFile(output, outBytes, 0644)
 			if err != nil {
 				fmt.Printf("Error writing to output file: %s\n", err.Error())
 				os.Exit(1)
 			}
 		} else {
-			// write the svg output to the console if no output is specified
-			fmt.Println(string(outBytes))
+			// write the output to the console if no output is specified,
+			// exactly as rendered since it may be a binary image
+			if _, err := cmd.OutOrStdout().Write(outBytes); err != nil {
+				fmt.Printf("Error writing to standard output: %s\n", err.Error())
+				os.Exit(1)
+			}
+		}
+
+		// write the preview alongside the diagram if requested
+		if thumbnail != "" {
+			thumbBytes := waveform.DrawThumbnail(vcdData, opts, waveform.ThumbnailWidth, waveform.ThumbnailHeight)
+			if err := os.WriteFile(thumbnail, thumbBytes, 0644); err != nil {
+				fmt.Printf("Error writing to thumbnail file: %s\n", err.Error())
+				os.Exit(1)
+			}
 		}
 	},
 }
 
+// readInput parses the VCD named by input, reading it from stdin when the
+// input is "-". FST files are only read by name.
+func readInput(stdin io.Reader, input string) (*waveform.VcdData, error) {
+	if input != "-" {
+		return waveform.ParseVCDFile(input)
+	}
+
+	content, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("could not read standard input: %w", err)
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, errors.New("no VCD data on standard input")
+	}
+	// fst2vcd converts only named files, so an FST trace is refused here
+	// rather than misread as a VCD
+	if waveform.IsFST(content) {
+		return nil, errors.New("FST data cannot be read from standard input, pass the FST file with -i instead")
+	}
+	return waveform.ParseVCD(bytes.NewReader(content), "stdin")
+}
+
+// outputFormat returns the format named by --format. Without the flag, an
+// output file whose extension names a registered format selects it, and
+// anything else is written as an SVG.
+func outputFormat(cmd *cobra.Command, output string) string {
+	format, _ := cmd.Flags().GetString("format")
+	if cmd.Flags().Changed("format") || output == "-" {
+		return format
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(output), "."))
+	if slices.Contains(waveform.Formats(), ext) {
+		return ext
+	}
+	return format
+}
+
 func fileExists(filename string) bool {
 	stat, err := os.Stat(filename)
 	if os.IsNotExist(err) {
@@ -75,11 +174,52 @@ func fileExists(filename string) bool {
 	return !stat.IsDir()
 }
 
+// ensureOutputDir checks that the directory the given output path is
+// written into exists. When create is set any missing directories are
+// created, otherwise an error naming the missing directory is returned.
+func ensureOutputDir(path string, create bool) error {
+	dir := filepath.Dir(path)
+	if create {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("could not create output directory %s: %w", dir, err)
+		}
+		return nil
+	}
+
+	stat, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("output directory does not exist: %s (use --create-dirs to create it)", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("could not access output directory %s: %w", dir, err)
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("output directory is not a directory: %s", dir)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(convertCmd)
 
-	convertCmd.Flags().StringP("input", "i", "", "Input VCD file path")
-	convertCmd.Flags().StringP("output", "o", "-", "Output SVG file path")
+	convertCmd.Flags().StringP("input", "i", "", "Input VCD file path, or - to read from stdin")
+	convertCmd.Flags().StringP("output", "o", "-", "Output file path, whose extension selects the format unless --format is given")
+	convertCmd.Flags().StringP("format", "f", "svg", fmt.Sprintf("Output format (%s) (alias --output-format)", strings.Join(waveform.Formats(), ", ")))
+	convertCmd.Flags().StringSlice("signals", nil, "Comma-separated signals to draw, in the order given (default all, sorted)")
+	convertCmd.Flags().Float64("png-scale", 1, "Scale factor of the image written by --format png")
+	convertCmd.Flags().Bool("verbose", false, "Print statistics of the parsed trace to standard error")
+	convertCmd.Flags().String("thumbnail", "", "Also write a small SVG preview of the trace to this file")
+	convertCmd.Flags().Bool("create-dirs", false, "Create any missing parent directories of the output files (alias --mkdir)")
+	convertCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		switch name {
+		case "mkdir":
+			name = "create-dirs"
+		case "output-format":
+			name = "format"
+		}
+		return pflag.NormalizedName(name)
+	})
+	addRenderFlags(convertCmd)
 	convertCmd.MarkFlagRequired("input")
 
 }
@@ -0,0 +1,184 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+func TestEnsureOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "nested", "deeper", "out.svg")
+
+	err := ensureOutputDir(output, false)
+	assert.EqualError(t, err, "output directory does not exist: "+filepath.Join(dir, "nested", "deeper")+" (use --create-dirs to create it)")
+	assert.NoDirExists(t, filepath.Join(dir, "nested"))
+
+	assert.NoError(t, ensureOutputDir(output, true))
+	assert.DirExists(t, filepath.Join(dir, "nested", "deeper"))
+	assert.NoError(t, ensureOutputDir(output, false))
+}
+
+func TestConvertCmd_CreateDirs(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "nested", "out.svg")
+	rootCmd.SetArgs([]string{"convert", "-i", blinkyVcd, "-o", output, "--mkdir"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "<svg")
+}
+
+func TestConvertCmd_LightTheme(t *testing.T) {
+	t.Cleanup(func() { convertCmd.Flags().Set("theme", "dark") })
+
+	output := filepath.Join(t.TempDir(), "out.svg")
+	rootCmd.SetArgs([]string{"convert", "-i", blinkyVcd, "-o", output, "--theme", "light"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `style="fill:white"`)
+	assert.NotContains(t, string(content), "rgba(20,20,20,1)")
+}
+
+func TestReadInput_Stdin(t *testing.T) {
+	content, err := os.ReadFile(blinkyVcd)
+	assert.NoError(t, err)
+
+	vcdData, err := readInput(bytes.NewReader(content), "-")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"blink", "clk", "counter", "rst"}, vcdData.Signals)
+
+	_, err = readInput(strings.NewReader(" \n"), "-")
+	assert.EqualError(t, err, "no VCD data on standard input")
+
+	fst := make([]byte, 330)
+	binary.BigEndian.PutUint64(fst[1:9], 329)
+	assert.True(t, waveform.IsFST(fst))
+	_, err = readInput(bytes.NewReader(fst), "-")
+	assert.EqualError(t, err, "FST data cannot be read from standard input, pass the FST file with -i instead")
+}
+
+func TestConvertCmd_Stdin(t *testing.T) {
+	content, err := os.ReadFile(blinkyVcd)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	rootCmd.SetIn(bytes.NewReader(content))
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetIn(nil)
+	defer rootCmd.SetOut(nil)
+
+	rootCmd.SetArgs([]string{"convert", "-i", "-", "-o", "-"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.True(t, strings.HasPrefix(out.String(), "<?xml"))
+	assert.Contains(t, out.String(), "</svg>")
+}
+
+func TestConvertCmd_Verbose(t *testing.T) {
+	t.Cleanup(func() { convertCmd.Flags().Set("verbose", "false") })
+
+	var out, errOut bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+
+	rootCmd.SetArgs([]string{"convert", "-i", blinkyVcd, "-o", "-", "--verbose"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vcdData, err := waveform.ParseVCDFile(blinkyVcd)
+	assert.NoError(t, err)
+	assert.Equal(t, "Parsed "+vcdData.Stats().String()+"\n", errOut.String())
+	assert.True(t, strings.HasPrefix(out.String(), "<?xml"))
+}
+
+func TestConvertCmd_FormatFromExtension(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.json")
+	rootCmd.SetArgs([]string{"convert", "-i", blinkyVcd, "-o", output})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "{"))
+
+	// unknown extensions fall back to the default format
+	assert.Equal(t, "svg", outputFormat(convertCmd, "out.txt"))
+	assert.Equal(t, "svg", outputFormat(convertCmd, "-"))
+	assert.Equal(t, "png", outputFormat(convertCmd, "OUT.PNG"))
+}
+
+func TestConvertCmd_OutputFormatAlias(t *testing.T) {
+	t.Cleanup(func() {
+		convertCmd.Flags().Set("format", "svg")
+		convertCmd.Flags().Lookup("format").Changed = false
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+
+	rootCmd.SetArgs([]string{"convert", "-i", blinkyVcd, "-o", "-", "--output-format", "wavejson"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Contains(t, out.String(), `"signal"`)
+
+	// an explicit format wins over the extension of the output
+	assert.Equal(t, "wavejson", outputFormat(convertCmd, "out.svg"))
+}
+
+func TestConvertCmd_PNGToStdout(t *testing.T) {
+	t.Cleanup(func() {
+		convertCmd.Flags().Set("format", "svg")
+		convertCmd.Flags().Lookup("format").Changed = false
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+
+	rootCmd.SetArgs([]string{"convert", "-i", blinkyVcd, "-o", "-", "--format", "png"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the image is written byte for byte, without a trailing newline
+	vcdData, err := waveform.ParseVCDFile(blinkyVcd)
+	assert.NoError(t, err)
+	png, err := waveform.RenderFormat("png", vcdData, waveform.RenderOptions{PNGScale: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, png, out.Bytes())
+}
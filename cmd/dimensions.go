@@ -0,0 +1,68 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+// dimensionsCmd represents the dimensions command
+var dimensionsCmd = &cobra.Command{
+	Use:   "dimensions",
+	Short: "Print the width and height of the SVG for a VCD file",
+	Long: `Prints the width and height, in pixels, of the SVG diagram that would
+be generated for a VCD (Value Change Dump) file, without rendering it.
+
+Example:
+go-vcd2svg dimensions -i input.vcd`,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cmd.Flags().Lookup("input").Value.String()
+
+		// check if the input exists
+		if !fileExists(input) {
+			fmt.Println("File does not exist:", input)
+			os.Exit(1)
+		}
+
+		// collect the render options from the flags
+		opts, err := renderOptions(cmd)
+		if err != nil {
+			fmt.Printf("Error reading render options: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		vcdData, err := waveform.ParseVCDFile(input)
+		if err != nil {
+			fmt.Printf("Error parsing VCD: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		width, height := waveform.Dimensions(vcdData, opts)
+		fmt.Fprintf(cmd.OutOrStdout(), "%d %d\n", width, height)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dimensionsCmd)
+
+	dimensionsCmd.Flags().StringP("input", "i", "", "Input VCD file path")
+	dimensionsCmd.MarkFlagRequired("input")
+	addRenderFlags(dimensionsCmd)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+const blinkyVcd = "../example/blinky.vcd"
+
+func TestDimensionsCmd(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"dimensions", "-i", blinkyVcd})
+	defer rootCmd.SetOut(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var width, height int
+	if _, err := fmt.Sscanf(out.String(), "%d %d", &width, &height); err != nil {
+		t.Fatalf("could not read dimensions from %q: %v", out.String(), err)
+	}
+
+	svg, err := waveform.SvgFromFile(blinkyVcd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Contains(t, string(svg), fmt.Sprintf(`<svg width="%d" height="%d"`, width, height))
+}
+
+func TestDimensionsCmd_TimeWindow(t *testing.T) {
+	t.Cleanup(func() {
+		dimensionsCmd.Flags().Set("start", "0")
+		dimensionsCmd.Flags().Set("end", "0")
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"dimensions", "-i", blinkyVcd, "--start", "10", "--end", "20"})
+	defer rootCmd.SetOut(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vcdData, err := waveform.ParseVCDFile(blinkyVcd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	width, height := waveform.Dimensions(vcdData, waveform.RenderOptions{StartTime: 10, EndTime: 20})
+	assert.Equal(t, fmt.Sprintf("%d %d\n", width, height), out.String())
+
+	full, _ := waveform.Dimensions(vcdData, waveform.RenderOptions{})
+	assert.Less(t, width, full)
+}
+
+func TestDimensionsCmd_Sizes(t *testing.T) {
+	t.Cleanup(func() {
+		for _, flag := range []string{"step-width", "signal-height", "gap"} {
+			dimensionsCmd.Flags().Set(flag, "0")
+		}
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"dimensions", "-i", blinkyVcd, "--step-width", "5", "--signal-height", "30", "--gap", "4"})
+	defer rootCmd.SetOut(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vcdData, err := waveform.ParseVCDFile(blinkyVcd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	width, height := waveform.Dimensions(vcdData, waveform.RenderOptions{StepWidth: 5, SignalHeight: 30, SignalGap: 4})
+	assert.Equal(t, fmt.Sprintf("%d %d\n", width, height), out.String())
+
+	// negative sizes are rejected rather than replaced by the defaults
+	dimensionsCmd.Flags().Set("gap", "-1")
+	_, err = renderOptions(dimensionsCmd)
+	assert.EqualError(t, err, "--gap must not be negative, got -1")
+}
+
+func TestRenderOptions_SignalOrder(t *testing.T) {
+	t.Cleanup(func() { dimensionsCmd.Flags().Set("signal-order", "alphabetical") })
+
+	dimensionsCmd.Flags().Set("signal-order", "declared")
+	opts, err := renderOptions(dimensionsCmd)
+	assert.NoError(t, err)
+	assert.Equal(t, waveform.SignalOrderDeclared, opts.SignalOrder)
+
+	dimensionsCmd.Flags().Set("signal-order", "random")
+	_, err = renderOptions(dimensionsCmd)
+	assert.EqualError(t, err, `unknown signal order "random" (expected one of alphabetical, declared)`)
+}
+
+func TestRenderOptions_FullScope(t *testing.T) {
+	t.Cleanup(func() {
+		dimensionsCmd.Flags().Set("full-scope", "false")
+		dimensionsCmd.Flags().Lookup("full-scope").Changed = false
+	})
+
+	opts, err := renderOptions(dimensionsCmd)
+	assert.NoError(t, err)
+	assert.Equal(t, waveform.ScopeInnermost, opts.ScopeDisplay)
+
+	dimensionsCmd.Flags().Set("full-scope", "true")
+	opts, err = renderOptions(dimensionsCmd)
+	assert.NoError(t, err)
+	assert.Equal(t, waveform.ScopeFull, opts.ScopeDisplay)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+// dumpCmd represents the dump command
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dump the parsed contents of a VCD file",
+	Long: `Dumps the signals, declarations and value changes parsed from a VCD
+(Value Change Dump) file, with the time steps in ascending order. The output
+is stable, so the dumps of two simulation runs can be diffed directly.
+
+Example:
+go-vcd2svg dump -i input.vcd --format json`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := cmd.Flags().Lookup("input").Value.String()
+		format := cmd.Flags().Lookup("format").Value.String()
+
+		if format != "json" {
+			return fmt.Errorf("unknown dump format %q, expected json", format)
+		}
+
+		// check if the input exists
+		if !fileExists(input) {
+			fmt.Println("File does not exist:", input)
+			os.Exit(1)
+		}
+
+		vcdData, err := waveform.ParseVCDFile(input)
+		if err != nil {
+			fmt.Printf("Error parsing VCD: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		if err := waveform.StreamJSON(cmd.OutOrStdout(), vcdData); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dumpCmd)
+
+	dumpCmd.Flags().StringP("input", "i", "", "Input VCD file path")
+	dumpCmd.Flags().StringP("format", "f", "json", "Dump format (json)")
+	dumpCmd.MarkFlagRequired("input")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpCmd(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"dump", "-i", blinkyVcd, "--format", "json"})
+	defer rootCmd.SetOut(nil)
+
+	require.NoError(t, rootCmd.Execute())
+
+	var doc struct {
+		Signals []string `json:"signals"`
+		Sim     []struct {
+			Time uint64 `json:"time"`
+		} `json:"sim"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, []string{"blink", "clk", "counter", "rst"}, doc.Signals)
+	require.NotEmpty(t, doc.Sim)
+	for i := 1; i < len(doc.Sim); i++ {
+		assert.Less(t, doc.Sim[i-1].Time, doc.Sim[i].Time)
+	}
+}
+
+func TestDumpCmd_UnknownFormat(t *testing.T) {
+	rootCmd.SetArgs([]string{"dump", "-i", blinkyVcd, "--format", "yaml"})
+	defer rootCmd.SetArgs(nil)
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown dump format")
+	dumpCmd.Flags().Set("format", "json")
+}
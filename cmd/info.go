@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+// infoCmd represents the info command
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Summarise the contents of a VCD file",
+	Long: `Prints the timescale, the range of simulation times and the number of
+declared signals, time steps and value changes of a VCD (Value Change Dump)
+file, one per line. The value changes are counted in a single pass, without
+holding the simulation in memory.
+
+Example:
+go-vcd2svg info -i input.vcd`,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cmd.Flags().Lookup("input").Value.String()
+
+		// check if the input exists
+		if !fileExists(input) {
+			fmt.Println("File does not exist:", input)
+			os.Exit(1)
+		}
+
+		stats, err := scanStats(input)
+		if err != nil {
+			fmt.Printf("Error parsing VCD: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		timescale := "none"
+		if stats.Timescale != (waveform.Timescale{}) {
+			timescale = stats.Timescale.String()
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintln(out, "timescale:", timescale)
+		fmt.Fprintf(out, "time range: %d to %d\n", stats.MinTime, stats.MaxTime)
+		fmt.Fprintln(out, "signals:", stats.Signals)
+		fmt.Fprintln(out, "time steps:", stats.TimeSteps)
+		fmt.Fprintln(out, "value changes:", stats.ValueChanges)
+	},
+}
+
+// scanStats summarises the file at path. A VCD file is read in a single
+// streaming pass; an FST file is converted and parsed in full.
+func scanStats(path string) (waveform.Stats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return waveform.Stats{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if header, _ := reader.Peek(9); waveform.IsFST(header) {
+		vcdData, err := waveform.ParseFSTFile(path)
+		if err != nil {
+			return waveform.Stats{}, err
+		}
+		return vcdData.Stats(), nil
+	}
+	return waveform.ScanStats(reader, path)
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().StringP("input", "i", "", "Input VCD file path")
+	infoCmd.MarkFlagRequired("input")
+}
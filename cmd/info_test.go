@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+func TestInfoCmd(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"info", "-i", blinkyVcd})
+	defer rootCmd.SetOut(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vcdData, err := waveform.ParseVCDFile(blinkyVcd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats := vcdData.Stats()
+	assert.Equal(t, fmt.Sprintf("timescale: %s\ntime range: %d to %d\nsignals: 4\ntime steps: %d\nvalue changes: %d\n",
+		stats.Timescale, stats.MinTime, stats.MaxTime, stats.TimeSteps, stats.ValueChanges), out.String())
+}
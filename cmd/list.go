@@ -0,0 +1,64 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the signals declared in a VCD file",
+	Long: `Lists the names of the signals declared in a VCD (Value Change Dump)
+file, one per line. Only the declaration section of the file is read, so
+this is fast even for very large files.
+
+Example:
+go-vcd2svg list -i input.vcd`,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cmd.Flags().Lookup("input").Value.String()
+
+		// check if the input exists
+		file, err := os.Open(input)
+		if err != nil {
+			fmt.Println("File does not exist:", input)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		vcdData, err := waveform.ParseDeclarationsOnly(file, input)
+		if err != nil {
+			fmt.Printf("Error parsing VCD: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		for _, sig := range vcdData.Signals {
+			fmt.Fprintln(cmd.OutOrStdout(), sig)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringP("input", "i", "", "Input VCD file path")
+	listCmd.MarkFlagRequired("input")
+}
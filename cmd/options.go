@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/titan098/go-vcd2svg/waveform"
+)
+
+// addRenderFlags registers the flags that control how a diagram is rendered,
+// so that every command laying out a diagram interprets them the same way.
+func addRenderFlags(cmd *cobra.Command) {
+	cmd.Flags().String("theme", "dark", "Built-in color scheme: dark or light")
+	cmd.Flags().String("theme-file", "", "JSON file defining a custom color scheme, overriding --theme")
+	cmd.Flags().String("descriptions", "", "JSON or CSV file mapping signal names to descriptions")
+	cmd.Flags().Bool("description-subtitles", false, "Draw signal descriptions beneath their labels")
+	cmd.Flags().String("signal-order", "alphabetical", "Order of the signals: alphabetical or declared")
+	cmd.Flags().Bool("scope-tree", false, "Group signals under an indented header for each module")
+	cmd.Flags().Bool("full-scope", false, "Label signals with their whole scope path rather than their innermost scope")
+	cmd.Flags().Bool("show-comments", false, "Draw the $comment blocks among the value changes beneath the diagram")
+	cmd.Flags().Uint64("start", 0, "Output only the simulation from this time on")
+	cmd.Flags().Uint64("end", 0, "Output only the simulation up to this time (default the end)")
+	cmd.Flags().Int("step-width", 0, "Width of each time step in pixels (default 20)")
+	cmd.Flags().Int("signal-height", 0, "Height of each signal row in pixels (default 20)")
+	cmd.Flags().Int("gap", 0, "Space between signal rows in pixels (default 10)")
+}
+
+// renderOptions builds the render options from the flags registered by
+// addRenderFlags.
+func renderOptions(cmd *cobra.Command) (waveform.RenderOptions, error) {
+	opts := waveform.RenderOptions{}
+
+	// select the built-in theme, unless a custom theme was specified
+	themeName, _ := cmd.Flags().GetString("theme")
+	theme, err := waveform.ThemeByName(themeName)
+	if err != nil {
+		return opts, err
+	}
+	opts.Theme = theme
+
+	themeFile, _ := cmd.Flags().GetString("theme-file")
+	if themeFile != "" {
+		theme, err := waveform.LoadTheme(themeFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Theme = theme
+	}
+
+	// load the signal descriptions if a sidecar file was specified
+	descriptionsFile, _ := cmd.Flags().GetString("descriptions")
+	if descriptionsFile != "" {
+		descriptions, err := waveform.LoadDescriptions(descriptionsFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Descriptions = descriptions
+	}
+	opts.DescriptionSubtitles, _ = cmd.Flags().GetBool("description-subtitles")
+	signalOrder, _ := cmd.Flags().GetString("signal-order")
+	opts.SignalOrder, err = waveform.SignalOrderByName(signalOrder)
+	if err != nil {
+		return opts, err
+	}
+	opts.ScopeTree, _ = cmd.Flags().GetBool("scope-tree")
+	if fullScope, _ := cmd.Flags().GetBool("full-scope"); fullScope {
+		opts.ScopeDisplay = waveform.ScopeFull
+	}
+	opts.ShowComments, _ = cmd.Flags().GetBool("show-comments")
+	opts.StartTime, _ = cmd.Flags().GetUint64("start")
+	opts.EndTime, _ = cmd.Flags().GetUint64("end")
+
+	// the renderer falls back to the defaults for values that are not
+	// positive, so reject negative sizes rather than silently ignore them
+	for _, size := range []struct {
+		flag  string
+		value *int
+	}{
+		{"step-width", &opts.StepWidth},
+		{"signal-height", &opts.SignalHeight},
+		{"gap", &opts.SignalGap},
+	} {
+		*size.value, _ = cmd.Flags().GetInt(size.flag)
+		if *size.value < 0 {
+			return opts, fmt.Errorf("--%s must not be negative, got %d", size.flag, *size.value)
+		}
+	}
+	return opts, nil
+}
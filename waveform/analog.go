@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// analogValue returns the numeric value of a bus value, reading binary
+// values as unsigned numbers. It returns false for values with unknown
+// bits, and for values that are not numbers.
+func analogValue(val string) (float64, bool) {
+	digits, kind := normalizeVectorValue(val)
+	switch kind {
+	case realValue:
+		f, err := strconv.ParseFloat(digits, 64)
+		return f, err == nil
+	case otherValue:
+		return 0, false
+	}
+	n, ok := new(big.Int).SetString(digits, 2)
+	if !ok {
+		return 0, false
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f, true
+}
+
+// drawAnalog draws a bus as a staircase rising with its numeric value,
+// scaled so that the smallest value the bus holds is at the bottom of its
+// row and the largest at the top. A bus holding a single value is drawn
+// through the middle of the row. The staircase is broken for the spans in
+// which the value is unknown.
+func (r *renderer) drawAnalog(sig string, y int) {
+	values := make([]float64, len(r.times))
+	known := make([]bool, len(r.times))
+	low, high, seen := 0.0, 0.0, false
+	for i, t := range r.times {
+		values[i], known[i] = analogValue(r.value(sig, t))
+		if !known[i] {
+			continue
+		}
+		if !seen {
+			low, high, seen = values[i], values[i], true
+		}
+		low, high = min(low, values[i]), max(high, values[i])
+	}
+
+	level := func(v float64) int {
+		if high == low {
+			return y + r.signalHeight/2
+		}
+		return y + r.signalHeight - int((v-low)/(high-low)*float64(r.signalHeight)+0.5)
+	}
+
+	var xs, ys []int
+	flush := func() {
+		if len(xs) > 1 {
+			r.canvas.Polyline(xs, ys, r.theme.Analog)
+		}
+		xs, ys = nil, nil
+	}
+	for i, t := range r.times {
+		x := r.timeX(t)
+		if len(xs) > 0 {
+			// hold the previous value up to this time
+			xs, ys = append(xs, x), append(ys, ys[len(ys)-1])
+		}
+		if !known[i] {
+			flush()
+			continue
+		}
+		if v := level(values[i]); len(ys) == 0 || ys[len(ys)-1] != v {
+			xs, ys = append(xs, x), append(ys, v)
+		}
+	}
+	flush()
+}
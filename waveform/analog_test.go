@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalogValue(t *testing.T) {
+	for val, want := range map[string]float64{"0101": 5, "b1111": 15, "2.5": 2.5, "0": 0} {
+		got, ok := analogValue(val)
+		assert.True(t, ok, val)
+		assert.Equal(t, want, got, val)
+	}
+	for _, val := range []string{"", "01x1", "z", "zzzz"} {
+		_, ok := analogValue(val)
+		assert.False(t, ok, val)
+	}
+}
+
+// analogPoints returns the points of every polyline in an SVG.
+func analogPoints(t *testing.T, svgStr string) [][][2]int {
+	var lines [][][2]int
+	for _, m := range regexp.MustCompile(`<polyline points="([^"]*)"`).FindAllStringSubmatch(svgStr, -1) {
+		var points [][2]int
+		for _, pair := range strings.Fields(m[1]) {
+			x, y, ok := strings.Cut(pair, ",")
+			require.True(t, ok)
+			px, err := strconv.Atoi(x)
+			require.NoError(t, err)
+			py, err := strconv.Atoi(y)
+			require.NoError(t, err)
+			points = append(points, [2]int{px, py})
+		}
+		lines = append(lines, points)
+	}
+	return lines
+}
+
+func TestDrawSVGWithOptions_AnalogSignals(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{Signals: []string{"count"}}
+	for i := range 8 {
+		sim[uint64(i)] = map[string]string{"count": strconv.FormatInt(int64(i), 2)}
+	}
+	vcdData.SetSnapshots(sim)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{AnalogSignals: map[string]bool{"count": true}}))
+	lines := analogPoints(t, svgStr)
+	require.Len(t, lines, 1)
+
+	// the staircase climbs from the bottom of the row to the top
+	points := lines[0]
+	assert.Equal(t, [2]int{leftMargin, 50 + signalHeight}, points[0])
+	assert.Equal(t, [2]int{7*stepWidth + leftMargin, 50}, points[len(points)-1])
+	for i := 1; i < len(points); i++ {
+		assert.GreaterOrEqual(t, points[i][0], points[i-1][0])
+		assert.LessOrEqual(t, points[i][1], points[i-1][1])
+	}
+	assert.NotContains(t, svgStr, busFillStyle)
+}
+
+func TestDrawSVGWithOptions_AnalogSignalsBreakOnUnknown(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"dac"},
+	}, map[uint64]map[string]string{
+		0: {"dac": "0000"},
+		1: {"dac": "0100"},
+		2: {"dac": "xxxx"},
+		3: {"dac": "1000"},
+		4: {"dac": "1000"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{AnalogSignals: map[string]bool{"dac": true}}))
+	lines := analogPoints(t, svgStr)
+	require.Len(t, lines, 2)
+	assert.Equal(t, 2*stepWidth+leftMargin, lines[0][len(lines[0])-1][0], "the trace ends where the value is unknown")
+	assert.Equal(t, 3*stepWidth+leftMargin, lines[1][0][0], "and resumes at the next known value")
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// BitRange is the [msb:lsb] index range a bus was declared with, e.g.
+// "data [7:0]" has an MSB of 7 and an LSB of 0.
+type BitRange struct {
+	MSB int
+	LSB int
+}
+
+// Ascending reports whether the range counts up from left to right, e.g.
+// [0:7], so that the leftmost bit of a value is bit 0.
+func (r BitRange) Ascending() bool {
+	return r.MSB < r.LSB
+}
+
+// BitOrder controls how the bits of a bus value are interpreted before it
+// is converted to a number for display.
+type BitOrder int
+
+const (
+	// BitOrderRange honors the declared range of each bus: values of
+	// buses declared with an ascending range such as [0:7] are read
+	// LSB-first, all others MSB-first.
+	BitOrderRange BitOrder = iota
+	// BitOrderMSBFirst reads the leftmost bit of every value as the most
+	// significant, as written in the VCD file.
+	BitOrderMSBFirst
+	// BitOrderLSBFirst reads the leftmost bit of every value as the least
+	// significant.
+	BitOrderLSBFirst
+)
+
+// lsbFirst reports whether the values of sig are written LSB-first under
+// the given bit order.
+func (v *VcdData) lsbFirst(sig string, order BitOrder) bool {
+	if v.isReal(sig) {
+		return false
+	}
+	switch order {
+	case BitOrderLSBFirst:
+		return true
+	case BitOrderRange:
+		return v.Ranges[sig].Ascending()
+	}
+	return false
+}
+
+// ApplyBitOrder returns a copy of the VcdData with the multi-bit values of
+// every bus that is read LSB-first under the given order reversed, so that
+// the leftmost bit of each value is the most significant. Single bit and
+// real values are unchanged.
+func (v *VcdData) ApplyBitOrder(order BitOrder) *VcdData {
+	reverse := map[string]bool{}
+	for _, sig := range v.Signals {
+		if v.lsbFirst(sig, order) {
+			reverse[sig] = true
+		}
+	}
+	if len(reverse) == 0 {
+		return v
+	}
+
+	ordered := *v
+	ordered.Changes = maps.Clone(v.Changes)
+	for sig := range reverse {
+		changes := slices.Clone(v.Changes[sig])
+		for i := range changes {
+			changes[i].Value = reverseBits(changes[i].Value)
+		}
+		ordered.Changes[sig] = changes
+	}
+	return &ordered
+}
+
+// reverseBits reverses the bits of a binary value, keeping any leading "b".
+// Real values are returned unchanged.
+func reverseBits(val string) string {
+	if isRealValue(val) {
+		return val
+	}
+	bits := strings.TrimPrefix(val, "b")
+	reversed := []byte(bits)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return val[:len(val)-len(bits)] + string(reversed)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bitOrderVcd = `$timescale 1ns $end
+$scope module top $end
+$var wire 12 ! up [0:11] $end
+$var wire 12 " down [11:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+b000000000011 !
+b000000000011 "
+#1
+b000000000000 !
+b000000000000 "
+`
+
+func TestVcdData_ApplyBitOrder(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(bitOrderVcd)), "bitorder.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, BitRange{MSB: 0, LSB: 11}, vcdData.Ranges["top up"])
+	assert.Equal(t, BitRange{MSB: 11, LSB: 0}, vcdData.Ranges["top down"])
+
+	ranged := vcdData.ApplyBitOrder(BitOrderRange)
+	assert.Equal(t, "110000000000", ranged.Value("top up", 0))
+	assert.Equal(t, "000000000011", ranged.Value("top down", 0))
+	assert.Equal(t, "000000000011", vcdData.Value("top up", 0))
+
+	assert.Same(t, vcdData, vcdData.ApplyBitOrder(BitOrderMSBFirst))
+	lsb := vcdData.ApplyBitOrder(BitOrderLSBFirst)
+	assert.Equal(t, "110000000000", lsb.Value("top down", 0))
+
+	// a bus declared [0:11] decodes differently depending on the bit order
+	assert.Equal(t, "0xC00", busLabel(ranged.Value("top up", 0), 0))
+	assert.Equal(t, "0x3", busLabel(vcdData.Value("top up", 0), 0))
+	assert.Same(t, vcdData, prepareData(vcdData, RenderOptions{BitOrder: BitOrderMSBFirst}))
+	assert.Equal(t, ranged.Snapshots(), prepareData(vcdData, RenderOptions{}).Snapshots())
+
+	assert.Equal(t, "1.5", reverseBits("1.5"))
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// Change is a value taken by a signal at a time, held until its next
+// change.
+type Change struct {
+	Time  uint64
+	Value string
+}
+
+// ValueAt returns the value in effect at time t in a list of changes
+// ordered by time, or false if the signal had not been assigned by then.
+func ValueAt(changes []Change, t uint64) (string, bool) {
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].Time > t })
+	if i == 0 || changes[i-1].Value == "" {
+		return "", false
+	}
+	return changes[i-1].Value, true
+}
+
+// Value returns the value of a signal in effect at time t, or an empty
+// string if the signal had not been assigned by then.
+func (v *VcdData) Value(sig string, t uint64) string {
+	val, _ := ValueAt(v.Changes[sig], t)
+	return val
+}
+
+// appendChange records that a signal takes val at time t, after every
+// change already in the list. A change already recorded at t is replaced,
+// and a value repeating the one before it is not recorded.
+func appendChange(changes []Change, t uint64, val string) []Change {
+	if n := len(changes); n > 0 && changes[n-1].Time == t {
+		changes = changes[:n-1]
+	}
+	if n := len(changes); n > 0 && changes[n-1].Value == val || n == 0 && val == "" {
+		return changes
+	}
+	return append(changes, Change{Time: t, Value: val})
+}
+
+// SnapshotAt returns the value of every signal in effect at time t, carried
+// forward from the most recent change at or before t. Signals that have not
+// been assigned by time t are omitted.
+func (v *VcdData) SnapshotAt(t uint64) map[string]string {
+	snapshot := map[string]string{}
+	for sig, changes := range v.Changes {
+		if val, ok := ValueAt(changes, t); ok {
+			snapshot[sig] = val
+		}
+	}
+	return snapshot
+}
+
+// snapshots yields the value of every assigned signal at each of the
+// times, in order. The map yielded is updated in place from one time to
+// the next, so it must be copied to be kept. Only a position in the
+// changes of each signal is held besides it, so that the simulation can be
+// walked without copying it.
+func (v *VcdData) snapshots() iter.Seq2[uint64, map[string]string] {
+	return func(yield func(uint64, map[string]string) bool) {
+		sigs := slices.Sorted(maps.Keys(v.Changes))
+		next := make([]int, len(sigs))
+		state := map[string]string{}
+		for _, t := range v.Times {
+			for i, sig := range sigs {
+				changes := v.Changes[sig]
+				for ; next[i] < len(changes) && changes[next[i]].Time <= t; next[i]++ {
+					if val := changes[next[i]].Value; val == "" {
+						delete(state, sig)
+					} else {
+						state[sig] = val
+					}
+				}
+			}
+			if !yield(t, state) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshots returns the value of every assigned signal at every time,
+// keyed by time. It copies every value to every time, so it is far larger
+// than Changes, and is meant for small traces and for code written
+// against the per-time form of the simulation.
+func (v *VcdData) Snapshots() map[uint64]map[string]string {
+	sim := make(map[uint64]map[string]string, len(v.Times))
+	for t, state := range v.snapshots() {
+		sim[t] = maps.Clone(state)
+	}
+	return sim
+}
+
+// SetSnapshots replaces the simulation with the values of the signals at
+// each time, keyed by time, recording the times and the changes between
+// them. A signal missing from a time holds its previous value.
+func (v *VcdData) SetSnapshots(sim map[uint64]map[string]string) {
+	v.Times = sortedTimes(sim)
+	v.Changes = map[string][]Change{}
+	for _, t := range v.Times {
+		for sig, val := range sim[t] {
+			if changes := appendChange(v.Changes[sig], t, val); len(changes) > 0 {
+				v.Changes[sig] = changes
+			}
+		}
+	}
+}
+
+// sortedTimes returns the time steps of the simulation in ascending order.
+func sortedTimes(sim map[uint64]map[string]string) []uint64 {
+	times := make([]uint64, 0, len(sim))
+	for t := range sim {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times
+}
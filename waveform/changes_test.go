@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sparseVcd = `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 4 " data $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+b0011 "
+#3
+1!
+#10
+0!
+`
+
+func TestVcdData_Changes(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(sparseVcd)), "sparse.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changes := vcdData.Changes
+	assert.Equal(t, []Change{{0, "0"}, {3, "1"}, {10, "0"}}, changes["test clk"])
+	assert.Equal(t, []Change{{0, "0011"}}, changes["test data"])
+
+	for tm, want := range map[uint64]string{0: "0011", 3: "0011", 7: "0011", 10: "0011", 99: "0011"} {
+		val, ok := ValueAt(changes["test data"], tm)
+		assert.True(t, ok)
+		assert.Equal(t, want, val, "t=%d", tm)
+	}
+	val, _ := ValueAt(changes["test clk"], 5)
+	assert.Equal(t, "1", val)
+	_, ok := ValueAt([]Change{{Time: 4, Value: "1"}}, 3)
+	assert.False(t, ok)
+}
+
+func TestVcdData_SetSnapshots(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{Signals: []string{"a", "b"}}, map[uint64]map[string]string{
+		0: {"a": "0"},
+		5: {"a": "1", "b": "10"},
+		9: {"a": "1", "b": "11"},
+	})
+	assert.Equal(t, []uint64{0, 5, 9}, vcdData.Times)
+	assert.Equal(t, []Change{{0, "0"}, {5, "1"}}, vcdData.Changes["a"])
+	assert.Equal(t, []Change{{5, "10"}, {9, "11"}}, vcdData.Changes["b"])
+	assert.Equal(t, "1", vcdData.Value("a", 7))
+	assert.Equal(t, "", vcdData.Value("b", 3))
+
+	assert.Equal(t, map[uint64]map[string]string{
+		0: {"a": "0"},
+		5: {"a": "1", "b": "10"},
+		9: {"a": "1", "b": "11"},
+	}, vcdData.Snapshots())
+	assert.Equal(t, map[string]string{"a": "1", "b": "10"}, vcdData.SnapshotAt(7))
+}
+
+// withSnapshots fills vcdData from per-time snapshots, as tests find those
+// easier to write out than each signal's changes.
+func withSnapshots(vcdData *VcdData, sim map[uint64]map[string]string) *VcdData {
+	vcdData.SetSnapshots(sim)
+	return vcdData
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Assertion is the value a signal is expected to hold at a time.
+type Assertion struct {
+	Signal   string
+	Time     uint64
+	Expected string
+}
+
+// Mismatch is an assertion that did not hold, along with the value the
+// signal actually held. Actual is empty if the signal had no value.
+type Mismatch struct {
+	Assertion
+	Actual string
+}
+
+// String describes the mismatch for reporting.
+func (m Mismatch) String() string {
+	actual := m.Actual
+	if actual == "" {
+		actual = "no value"
+	}
+	return fmt.Sprintf("%s at %d: expected %s, got %s", m.Signal, m.Time, m.Expected, actual)
+}
+
+// LoadAssertions reads a file of "signal,time,expected" records.
+func LoadAssertions(filename string) ([]Assertion, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read assertions file: %w", err)
+	}
+	return ParseAssertionsCSV(content)
+}
+
+// ParseAssertionsCSV decodes "signal,time,expected" records. Lines starting
+// with '#' are treated as comments, and a leading 'b' or 'r' on an expected
+// vector value is ignored as it is in a VCD file.
+func ParseAssertionsCSV(content []byte) ([]Assertion, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode assertions: %w", err)
+	}
+
+	assertions := make([]Assertion, 0, len(records))
+	for _, record := range records {
+		t, err := strconv.ParseUint(record[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode assertions: invalid time %q for %s", record[1], record[0])
+		}
+		expected, _ := normalizeVectorValue(record[2])
+		assertions = append(assertions, Assertion{
+			Signal:   record[0],
+			Time:     t,
+			Expected: expected,
+		})
+	}
+	return assertions, nil
+}
+
+// Check verifies each assertion against the value its signal held at the
+// assertion's time, carried forward from the most recent change, and
+// returns the assertions that did not hold in order.
+func (v *VcdData) Check(assertions []Assertion) []Mismatch {
+	var mismatches []Mismatch
+	for _, a := range assertions {
+		actual := v.SnapshotAt(a.Time)[a.Signal]
+		if actual != a.Expected {
+			mismatches = append(mismatches, Mismatch{Assertion: a, Actual: actual})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVcdData_Check(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "count"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "count": "00"},
+		1: {"clk": "1"},
+		5: {"clk": "0", "count": "01"},
+	})
+
+	assertions, err := ParseAssertionsCSV([]byte("# signal,time,expected\nclk,3,1\ncount,4,b00\ncount,5,10\nmissing,1,0\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, Assertion{Signal: "count", Time: 4, Expected: "00"}, assertions[1])
+
+	mismatches := vcdData.Check(assertions)
+	assert.Equal(t, []Mismatch{
+		{Assertion: Assertion{Signal: "count", Time: 5, Expected: "10"}, Actual: "01"},
+		{Assertion: Assertion{Signal: "missing", Time: 1, Expected: "0"}},
+	}, mismatches)
+	assert.Equal(t, "count at 5: expected 10, got 01", mismatches[0].String())
+	assert.Equal(t, "missing at 1: expected 0, got no value", mismatches[1].String())
+
+	_, err = ParseAssertionsCSV([]byte("clk,soon,1\n"))
+	assert.EqualError(t, err, `could not decode assertions: invalid time "soon" for clk`)
+}
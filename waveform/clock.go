@@ -0,0 +1,121 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import "slices"
+
+const (
+	// minClockEdges is the number of edges a scalar signal needs before it
+	// is considered for clock detection.
+	minClockEdges = 4
+
+	// clockTolerance bounds how far, as a fraction 1/clockTolerance of the
+	// half period, the time between two edges of a clock may stray.
+	clockTolerance = 4
+)
+
+// timeSpan is a half-open interval of simulation time.
+type timeSpan struct {
+	Start uint64
+	End   uint64
+}
+
+// signalEdges returns the times at which a single-bit signal changes value.
+// The ok result is false if the signal ever holds a non-scalar value.
+func signalEdges(changes []Change, times []uint64) ([]uint64, bool) {
+	var edges []uint64
+	last := ""
+	for i, t := range times {
+		val, _ := ValueAt(changes, t)
+		if val != "0" && val != "1" {
+			return nil, false
+		}
+		if i > 0 && last != val {
+			edges = append(edges, t)
+		}
+		last = val
+	}
+	return edges, true
+}
+
+// detectClockPeriod determines whether a signal behaves like a clock and
+// returns its typical half period, i.e. the median time between two edges.
+// At least three quarters of its edges must follow the previous one within
+// the tolerance of that half period; the rest may be gaps where the clock is
+// gated.
+func detectClockPeriod(changes []Change, times []uint64) (uint64, bool) {
+	edges, ok := signalEdges(changes, times)
+	if !ok || len(edges) < minClockEdges {
+		return 0, false
+	}
+
+	intervals := make([]uint64, 0, len(edges)-1)
+	for i := 1; i < len(edges); i++ {
+		intervals = append(intervals, edges[i]-edges[i-1])
+	}
+	sorted := slices.Clone(intervals)
+	slices.Sort(sorted)
+	halfPeriod := sorted[len(sorted)/2]
+
+	tolerance := halfPeriod / clockTolerance
+	regular := 0
+	for _, interval := range intervals {
+		if interval >= halfPeriod-tolerance && interval <= halfPeriod+tolerance {
+			regular++
+		}
+	}
+	if 4*regular < 3*len(intervals) {
+		return 0, false
+	}
+	return halfPeriod, true
+}
+
+// gatedClockSpans returns the intervals in which a detected clock stops
+// toggling for longer than its typical period.
+func gatedClockSpans(changes []Change, times []uint64) []timeSpan {
+	halfPeriod, ok := detectClockPeriod(changes, times)
+	if !ok {
+		return nil
+	}
+
+	edges, _ := signalEdges(changes, times)
+	edges = append(edges, times[len(times)-1])
+
+	var spans []timeSpan
+	for i := 1; i < len(edges); i++ {
+		if edges[i]-edges[i-1] > 2*halfPeriod {
+			spans = append(spans, timeSpan{Start: edges[i-1], End: edges[i]})
+		}
+	}
+	return spans
+}
+
+// drawClockEdges draws a small triangle at the foot of every rising edge of
+// sig, if detectClockPeriod finds its edges evenly spaced like a clock's.
+func (r *renderer) drawClockEdges(sig string, y int) {
+	if _, ok := detectClockPeriod(r.changes[sig], r.times); !ok {
+		return
+	}
+
+	base := y + r.signalHeight
+	for i := 1; i < len(r.times); i++ {
+		if r.value(sig, r.times[i-1]) != "0" || r.value(sig, r.times[i]) != "1" {
+			continue
+		}
+		x := r.timeX(r.times[i])
+		r.canvas.Polygon([]int{x - 3, x + 3, x}, []int{base, base, base - 5}, r.theme.EdgeMarker)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedClockData returns a clock that toggles every time step until t=7 and
+// then holds its level until the end of the trace at t=15.
+func gatedClockData() *VcdData {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{
+		Decl:    map[string][]string{"!": {"clk"}, "#": {"data"}},
+		Signals: []string{"clk", "data"},
+	}
+	for t := uint64(0); t <= 15; t++ {
+		clk := "0"
+		if t <= 7 && t%2 == 1 {
+			clk = "1"
+		}
+		sim[t] = map[string]string{"clk": clk, "data": "1"}
+	}
+	vcdData.SetSnapshots(sim)
+	return vcdData
+}
+
+// irregularData returns a single-bit data signal with edges at uneven times,
+// including long quiet stretches.
+func irregularData() *VcdData {
+	sim := map[uint64]map[string]string{}
+	level := "0"
+	for t := uint64(0); t <= 24; t++ {
+		switch t {
+		case 1, 2, 3, 5, 12, 13, 22:
+			level = map[string]string{"0": "1", "1": "0"}[level]
+		}
+		sim[t] = map[string]string{"data": level}
+	}
+	vcdData := &VcdData{Decl: map[string][]string{"!": {"data"}}, Signals: []string{"data"}}
+	vcdData.SetSnapshots(sim)
+	return vcdData
+}
+
+func TestDetectClockPeriod(t *testing.T) {
+	vcdData := gatedClockData()
+	times := vcdData.Times
+
+	halfPeriod, ok := detectClockPeriod(vcdData.Changes["clk"], times)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), halfPeriod)
+
+	_, ok = detectClockPeriod(vcdData.Changes["data"], times)
+	assert.False(t, ok)
+
+	irregular := irregularData()
+	_, ok = detectClockPeriod(irregular.Changes["data"], irregular.Times)
+	assert.False(t, ok)
+}
+
+func TestDrawSVGWithOptions_GatedClockDetection(t *testing.T) {
+	vcdData := gatedClockData()
+
+	assert.Equal(t, []timeSpan{{Start: 8, End: 15}}, gatedClockSpans(vcdData.Changes["clk"], vcdData.Times))
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{GatedClockDetection: true}))
+	gated := fmt.Sprintf(`<rect x="%d" y="50" width="%d" height="%d" style="%s" />`,
+		8*stepWidth+leftMargin, 7*stepWidth, signalHeight, gatedStyle)
+	assert.Contains(t, svgStr, gated)
+	assert.NotContains(t, string(DrawSVG(vcdData)), gatedStyle)
+
+	svgStr = string(DrawSVGWithOptions(irregularData(), RenderOptions{GatedClockDetection: true}))
+	assert.NotContains(t, svgStr, gatedStyle)
+}
+
+func TestDrawSVGWithOptions_MarkClockEdges(t *testing.T) {
+	vcdData := gatedClockData()
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{MarkClockEdges: true}))
+	assert.Equal(t, 4, strings.Count(svgStr, edgeMarkerStyle))
+	x := 3*stepWidth + leftMargin
+	assert.Contains(t, svgStr, fmt.Sprintf(`<polygon points="%d,70 %d,70 %d,65" style="%s" />`, x-3, x+3, x, edgeMarkerStyle))
+	assert.NotContains(t, string(DrawSVG(vcdData)), edgeMarkerStyle)
+
+	svgStr = string(DrawSVGWithOptions(irregularData(), RenderOptions{MarkClockEdges: true}))
+	assert.NotContains(t, svgStr, edgeMarkerStyle)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Comment is the text of a $comment block of a VCD file. Comments among
+// the value changes are often markers written by the testbench, and are
+// Timed with the simulation time they appear at; those in the header are
+// not.
+type Comment struct {
+	Text  string
+	Timed bool
+	Time  uint64
+}
+
+// extractComments returns the $comment blocks of a VCD file, with the
+// content in which they are blanked out. The parser does not accept
+// comments after $enddefinitions, and joins the words of the others, so the
+// comments are read here instead. Blanking keeps the line and column of
+// the remaining content for the parser's errors.
+func extractComments(content []byte) ([]Comment, []byte) {
+	if !bytes.Contains(content, []byte("$comment")) {
+		return nil, content
+	}
+
+	var comments []Comment
+	var text []string
+	var time uint64
+	stripped := bytes.Clone(content)
+	simulation, inComment, code := false, false, false
+	start := 0
+
+	for i := 0; i < len(content); {
+		// find the next word and the whitespace that ends it
+		for i < len(content) && isSpace(content[i]) {
+			i++
+		}
+		j := i
+		for j < len(content) && !isSpace(content[j]) {
+			j++
+		}
+		if i == j {
+			break
+		}
+		word := string(content[i:j])
+
+		switch {
+		case inComment && word == "$end":
+			comments = append(comments, Comment{Text: strings.Join(text, " "), Timed: simulation, Time: time})
+			blank(stripped[start:j])
+			inComment = false
+		case inComment:
+			text = append(text, word)
+		case word == "$comment":
+			inComment, text, start = true, nil, i
+		case word == "$enddefinitions":
+			simulation = true
+		case simulation && !code && word[0] == '#':
+			if t, err := strconv.ParseUint(word[1:], 10, 64); err == nil {
+				time = t
+			}
+		}
+
+		// the word after a vector or real value is its identifier code,
+		// which may itself start with a '#'
+		code = simulation && !code && strings.ContainsRune("bBrR", rune(word[0]))
+		i = j
+	}
+	return comments, stripped
+}
+
+// isSpace reports whether c separates the words of a VCD file.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// blank replaces every character of b other than line breaks with a space.
+func blank(b []byte) {
+	for i, c := range b {
+		if c != '\n' && c != '\r' {
+			b[i] = ' '
+		}
+	}
+}
+
+// drawComments draws the text of each timed comment beneath the axis at
+// the x coordinate of its time, with a tick marking the time. Comments
+// outside the times drawn are skipped.
+func (r *renderer) drawComments() {
+	if !r.opts.ShowComments || len(r.times) == 0 {
+		return
+	}
+	first, last := r.times[0], r.times[len(r.times)-1]
+	for _, comment := range r.vcdData.Comments {
+		if !comment.Timed || comment.Time < first || comment.Time > last {
+			continue
+		}
+		x := r.timeX(comment.Time)
+		r.canvas.Line(x, r.flipY(r.height-30, 0), x, r.flipY(r.height-25, 0), r.theme.Tick)
+		r.canvas.Text(x+2, r.flipY(r.height-18, -8), comment.Text, r.theme.Comment)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commentVcd = `$comment hello $end
+$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 4 # data $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+b0000 #
+$comment reset
+  released $end
+#5
+1!
+b0011 #
+#10
+0!
+$comment first transfer $end
+#15
+1!
+`
+
+func TestParseVCD_Comments(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(commentVcd)), "comment.vcd")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Comment{
+		{Text: "hello"},
+		{Text: "reset released", Timed: true, Time: 0},
+		{Text: "first transfer", Timed: true, Time: 10},
+	}, vcdData.Comments)
+
+	// the comments do not disturb the values around them
+	assert.Equal(t, "0011", vcdData.Value("test data", 10))
+	assert.Equal(t, "1", vcdData.Value("test clk", 15))
+}
+
+func TestExtractComments_KeepsPositions(t *testing.T) {
+	content := []byte("#0\n$comment a\nb $end 1!\n")
+	comments, stripped := extractComments(content)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, "#0\n          \n       1!\n", string(stripped))
+	assert.Equal(t, "#0\n$comment a\nb $end 1!\n", string(content), "the input is not modified")
+
+	// the code of a vector value is not a time
+	comments, _ = extractComments([]byte("$enddefinitions $end\n#3\nb1 #7\n$comment c $end\n"))
+	assert.Equal(t, []Comment{{Text: "c", Timed: true, Time: 3}}, comments)
+}
+
+func TestDrawSVGWithOptions_ShowComments(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(commentVcd)), "comment.vcd")
+	require.NoError(t, err)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{ShowComments: true}))
+	_, height := Dimensions(vcdData, RenderOptions{})
+	x := leftMargin + 2*stepWidth
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="%d" style="%s" >first transfer</text>`, x+2, height-18, commentStyle))
+	assert.Contains(t, svgStr, ">reset released</text>")
+	assert.NotContains(t, svgStr, ">hello</text>")
+
+	assert.NotContains(t, string(DrawSVG(vcdData)), "first transfer")
+}
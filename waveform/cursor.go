@@ -0,0 +1,43 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+// Cursor marks a time of interest, such as the moment a bug appears, with
+// a vertical line across the diagram and a label at its top.
+type Cursor struct {
+	Time  uint64
+	Label string
+}
+
+// drawCursors draws each cursor as a line at the x coordinate of its time,
+// placed the same way as the grid lines, with its label beside the top of
+// the line. Cursors outside the times drawn are skipped.
+func (r *renderer) drawCursors() {
+	if len(r.times) == 0 {
+		return
+	}
+	first, last := r.times[0], r.times[len(r.times)-1]
+	for _, cursor := range r.opts.Cursors {
+		if cursor.Time < first || cursor.Time > last {
+			continue
+		}
+		x := r.timeX(cursor.Time)
+		r.canvas.Line(x, r.flipY(40, 0), x, r.flipY(r.height-30, 0), r.theme.Cursor)
+		if cursor.Label != "" {
+			r.canvas.Text(x+2, r.flipY(47, -8), cursor.Label, r.theme.CursorText)
+		}
+	}
+}
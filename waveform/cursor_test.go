@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGWithOptions_Cursors(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl:    map[string][]string{"!": {"sig"}},
+		Signals: []string{"sig"},
+	}, map[uint64]map[string]string{
+		0:  {"sig": "0"},
+		10: {"sig": "1"},
+		20: {"sig": "0"},
+		30: {"sig": "1"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		Cursors: []Cursor{{Time: 20, Label: "glitch"}, {Time: 99, Label: "beyond"}},
+	}))
+
+	// the cursor shares the x coordinate of the grid line at its time
+	x := leftMargin + 2*stepWidth
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="%d" y1="40" x2="%d" y2="100" style="%s" />`, x, x, gridStyle))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="%d" y1="40" x2="%d" y2="100" style="%s" />`, x, x, cursorStyle))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="47" style="%s" >glitch</text>`, x+2, cursorTextStyle))
+
+	// cursors outside the trace are not drawn
+	assert.NotContains(t, svgStr, "beyond")
+	assert.NotContains(t, string(DrawSVG(vcdData)), cursorStyle)
+}
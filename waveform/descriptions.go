@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadDescriptions reads a sidecar file mapping signal names to human
+// readable descriptions. Files with a ".csv" extension are read as two
+// column "name,description" records; anything else is read as a JSON object
+// of name to description.
+func LoadDescriptions(filename string) (map[string]string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read descriptions file: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return ParseDescriptionsCSV(content)
+	}
+	return ParseDescriptionsJSON(content)
+}
+
+// ParseDescriptionsJSON decodes a JSON object mapping signal names to descriptions.
+func ParseDescriptionsJSON(content []byte) (map[string]string, error) {
+	descriptions := map[string]string{}
+	if err := json.Unmarshal(content, &descriptions); err != nil {
+		return nil, fmt.Errorf("could not decode descriptions: %w", err)
+	}
+	return descriptions, nil
+}
+
+// ParseDescriptionsCSV decodes "name,description" records. Lines starting
+// with '#' are treated as comments.
+func ParseDescriptionsCSV(content []byte) (map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode descriptions: %w", err)
+	}
+
+	descriptions := make(map[string]string, len(records))
+	for _, record := range records {
+		descriptions[record[0]] = record[1]
+	}
+	return descriptions, nil
+}
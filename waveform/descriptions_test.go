@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDescriptions(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "descriptions.json")
+	csvFile := filepath.Join(dir, "descriptions.csv")
+	if err := os.WriteFile(jsonFile, []byte(`{"test clk": "System clock"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(csvFile, []byte("# name,description\ntest clk, \"System clock, 100MHz\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptions, err := LoadDescriptions(jsonFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, map[string]string{"test clk": "System clock"}, descriptions)
+
+	descriptions, err = LoadDescriptions(csvFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, map[string]string{"test clk": "System clock, 100MHz"}, descriptions)
+
+	_, err = ParseDescriptionsCSV([]byte("one,two,three\n"))
+	assert.Error(t, err)
+}
+
+func TestDrawSVGWithOptions_Descriptions(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"rst"},
+		},
+		Signals: []string{"clk", "rst"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "rst": "1"},
+		1: {"clk": "1", "rst": "0"},
+	})
+	opts := RenderOptions{Descriptions: map[string]string{"clk": "System clock & reference"}}
+
+	svgStr := string(DrawSVGWithOptions(vcdData, opts))
+	assert.Contains(t, svgStr, ">clk<title>System clock &amp; reference</title>\n</text>")
+	assert.Contains(t, svgStr, ">rst</text>")
+	assert.Equal(t, 1, strings.Count(svgStr, "</title>\n</text>"), "only the described label carries a title")
+	assert.NotContains(t, svgStr, subtitleStyle)
+
+	opts.DescriptionSubtitles = true
+	svgStr = string(DrawSVGWithOptions(vcdData, opts))
+	assert.Contains(t, svgStr, `style="`+subtitleStyle+`" >System clock &amp; reference</text>`)
+}
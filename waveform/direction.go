@@ -0,0 +1,87 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import "strings"
+
+// PortDirection is the direction of a port signal.
+type PortDirection int
+
+const (
+	// PortUnknown is a signal that is not known to be a port.
+	PortUnknown PortDirection = iota
+	// PortInput is an input port.
+	PortInput
+	// PortOutput is an output port.
+	PortOutput
+	// PortInout is a bidirectional port.
+	PortInout
+)
+
+// String returns the name of the direction as used in Verilog.
+func (d PortDirection) String() string {
+	switch d {
+	case PortInput:
+		return "input"
+	case PortOutput:
+		return "output"
+	case PortInout:
+		return "inout"
+	}
+	return "unknown"
+}
+
+// glyph returns the arrow drawn beside the label of a port.
+func (d PortDirection) glyph() string {
+	switch d {
+	case PortInput:
+		return "→"
+	case PortOutput:
+		return "←"
+	case PortInout:
+		return "↔"
+	}
+	return ""
+}
+
+// ClassifyPortDirection guesses the direction of a port from common naming
+// conventions: a "_i", "_in", "_o", "_out", "_io" or "_inout" suffix, or an
+// "i_", "o_" or "io_" prefix on the signal's name without its scope.
+func ClassifyPortDirection(name string) PortDirection {
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(name, "_io"), strings.HasSuffix(name, "_inout"), strings.HasPrefix(name, "io_"):
+		return PortInout
+	case strings.HasSuffix(name, "_i"), strings.HasSuffix(name, "_in"), strings.HasPrefix(name, "i_"):
+		return PortInput
+	case strings.HasSuffix(name, "_o"), strings.HasSuffix(name, "_out"), strings.HasPrefix(name, "o_"):
+		return PortOutput
+	}
+	return PortUnknown
+}
+
+// portDirection returns the direction of a signal from the data, falling
+// back to its name when the data does not record one.
+func (v *VcdData) portDirection(sig string) PortDirection {
+	if d, ok := v.Directions[sig]; ok {
+		return d
+	}
+	return ClassifyPortDirection(sig)
+}
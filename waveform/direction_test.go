@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyPortDirection(t *testing.T) {
+	assert.Equal(t, PortInput, ClassifyPortDirection("top data_i"))
+	assert.Equal(t, PortInput, ClassifyPortDirection("i_valid"))
+	assert.Equal(t, PortOutput, ClassifyPortDirection("top cpu result_out"))
+	assert.Equal(t, PortInout, ClassifyPortDirection("sda_io"))
+	assert.Equal(t, PortUnknown, ClassifyPortDirection("clk"))
+	assert.Equal(t, PortUnknown, ClassifyPortDirection("top_io clk"))
+}
+
+func TestDrawSVGWithOptions_ShowPortDirections(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals:    []string{"clk", "result", "data_i"},
+		Directions: map[string]PortDirection{"result": PortOutput},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "result": "00", "data_i": "0"},
+		1: {"clk": "1", "result": "01", "data_i": "1"},
+	})
+	glyph := func(y int, arrow string) string {
+		return fmt.Sprintf(`<text x="142" y="%d" style="%s" >%s</text>`, y, directionStyle, arrow)
+	}
+
+	plain := string(DrawSVG(vcdData))
+	assert.NotContains(t, plain, directionStyle)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{ShowPortDirections: true}))
+	assert.Contains(t, svgStr, glyph(90, "←"))
+	assert.Contains(t, svgStr, glyph(120, "→"))
+	assert.Equal(t, 2, strings.Count(svgStr, directionStyle))
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// OutputFormat renders the simulation data in an output format using the
+// provided render options.
+type OutputFormat func(*VcdData, RenderOptions) ([]byte, error)
+
+// formats holds the registered output formats by name. Every format draws
+// or exports only the time window selected by StartTime and EndTime.
+var formats = map[string]OutputFormat{
+	"svg": func(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+		return DrawSVGWithOptions(vcdData, opts), nil
+	},
+	"html": func(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+		return DrawHTML(vcdData, opts), nil
+	},
+	"json": func(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+		return ToJSON(opts.window(vcdData))
+	},
+	"png": func(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+		return RasterizeSVG(DrawSVGWithOptions(vcdData, opts), opts.PNGScale)
+	},
+	"vcd": func(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+		end := opts.EndTime
+		if end == 0 {
+			end = math.MaxUint64
+		}
+		return ToVCD(vcdData, nil, opts.StartTime, end)
+	},
+	"wavejson": func(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+		return ToWaveJSON(opts.window(vcdData))
+	},
+}
+
+// RegisterFormat adds an output format under the given name, replacing any
+// format already registered with that name. Formats are usually registered
+// from an init function, before any rendering takes place.
+func RegisterFormat(name string, fn OutputFormat) {
+	formats[name] = fn
+}
+
+// Formats returns the names of the registered output formats in sorted
+// order.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderFormat renders the simulation data using the output format
+// registered with the given name.
+func RenderFormat(name string, vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+	fn, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, expected one of: %s", name, strings.Join(Formats(), ", "))
+	}
+	return fn(vcdData, opts)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("count", func(vcdData *VcdData, _ RenderOptions) ([]byte, error) {
+		return fmt.Appendf(nil, "%d signals", len(vcdData.Signals)), nil
+	})
+	defer delete(formats, "count")
+
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b"},
+	}, map[uint64]map[string]string{0: {"a": "0", "b": "1"}})
+	out, err := RenderFormat("count", vcdData, RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2 signals", string(out))
+	assert.Equal(t, []string{"count", "html", "json", "png", "svg", "vcd", "wavejson"}, Formats())
+
+	out, err = RenderFormat("svg", vcdData, RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, DrawSVG(vcdData), out)
+
+	_, err = RenderFormat("missing", vcdData, RenderOptions{})
+	assert.EqualError(t, err, `unknown output format "missing", expected one of: count, html, json, png, svg, vcd, wavejson`)
+}
+
+func TestRenderFormat_VCDWindow(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(8) {
+		sim[i] = map[string]string{"count": fmt.Sprintf("%04b", i)}
+	}
+	vcdData := withSnapshots(&VcdData{Signals: []string{"count"}, Widths: map[string]int{"count": 4}}, sim)
+
+	out, err := RenderFormat("vcd", vcdData, RenderOptions{StartTime: 3, EndTime: 5})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "#3\nb0011 !\n#4\nb0100 !\n#5\nb0101 !\n")
+	assert.NotContains(t, string(out), "#2\n")
+	assert.NotContains(t, string(out), "#6\n")
+
+	// without an end the window runs to the end of the simulation
+	out, err = RenderFormat("vcd", vcdData, RenderOptions{StartTime: 6})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "#6\nb0110 !\n#7\nb0111 !\n")
+}
+
+func TestRenderFormat_JSONWindow(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(8) {
+		sim[i] = map[string]string{"count": fmt.Sprintf("%04b", i)}
+	}
+	vcdData := withSnapshots(&VcdData{Signals: []string{"count"}, Widths: map[string]int{"count": 4}}, sim)
+	opts := RenderOptions{StartTime: 3, EndTime: 5}
+
+	out, err := RenderFormat("json", vcdData, opts)
+	assert.NoError(t, err)
+	want, err := ToJSON(vcdData.Clip(3, 5))
+	assert.NoError(t, err)
+	assert.Equal(t, want, out)
+
+	out, err = RenderFormat("wavejson", vcdData, opts)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"signal":[{"name":"count","wave":"===","data":["0011","0100","0101"]}]}`, string(out))
+}
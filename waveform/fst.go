@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// fstHeaderLength is the length of the header block that starts every FST
+// file, as recorded in the section length that follows the block type.
+const fstHeaderLength = 329
+
+// fstConverter is the GTKWave tool used to convert FST files to VCD.
+var fstConverter = "fst2vcd"
+
+// ErrFSTConverterMissing is returned when an FST file is read but the
+// fst2vcd converter cannot be found on the PATH.
+var ErrFSTConverterMissing = errors.New("reading FST files requires fst2vcd from GTKWave on the PATH, or convert it first with: fst2vcd input.fst > input.vcd")
+
+// IsFST reports whether content starts with the header block of an FST
+// file: a zero block type followed by the big-endian section length.
+func IsFST(content []byte) bool {
+	return len(content) >= 9 && content[0] == 0 &&
+		binary.BigEndian.Uint64(content[1:9]) == fstHeaderLength
+}
+
+// ParseFSTFile reads the FST file with the given filename by converting it
+// to VCD with fst2vcd, so that it produces the same VcdData as the VCD of
+// the same trace.
+func ParseFSTFile(filename string) (*VcdData, error) {
+	path, err := exec.LookPath(fstConverter)
+	if err != nil {
+		return nil, ErrFSTConverterMissing
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, filename)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not convert FST file: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return ParseVCD(bytes.NewReader(stdout.Bytes()), filename)
+}
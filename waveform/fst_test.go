@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFSTFile writes a file starting with an FST header block.
+func writeFSTFile(t *testing.T) string {
+	header := make([]byte, 1+fstHeaderLength)
+	binary.BigEndian.PutUint64(header[1:9], fstHeaderLength)
+	filename := filepath.Join(t.TempDir(), "trace.fst")
+	if err := os.WriteFile(filename, header, 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return filename
+}
+
+func TestIsFST(t *testing.T) {
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint64(header[1:9], fstHeaderLength)
+	assert.True(t, IsFST(header))
+	assert.False(t, IsFST([]byte(simpleVcd)))
+	assert.False(t, IsFST(header[:4]))
+}
+
+func TestParseVCDFile_FST(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub converter is a shell script")
+	}
+	vcdFile, err := filepath.Abs("../example/blinky.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// stand in for fst2vcd with a script printing the VCD of the same trace
+	stub := filepath.Join(t.TempDir(), "fst2vcd")
+	script := "#!/bin/sh\ncat '" + vcdFile + "'\n"
+	if err := os.WriteFile(stub, []byte(script), 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	converter := fstConverter
+	fstConverter = stub
+	defer func() { fstConverter = converter }()
+
+	fstData, err := ParseVCDFile(writeFSTFile(t))
+	assert.NoError(t, err)
+	vcdData, err := ParseVCDFile(vcdFile)
+	assert.NoError(t, err)
+	assert.Equal(t, DrawSVG(vcdData), DrawSVG(fstData))
+}
+
+func TestParseVCDFile_FSTConverterMissing(t *testing.T) {
+	converter := fstConverter
+	fstConverter = "go-vcd2svg-missing-fst2vcd"
+	defer func() { fstConverter = converter }()
+
+	_, err := ParseVCDFile(writeFSTFile(t))
+	assert.ErrorIs(t, err, ErrFSTConverterMissing)
+}
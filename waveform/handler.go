@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"io"
+	"net/http"
+)
+
+// SVGHandler returns an http.Handler that draws the VCD file posted as the
+// request body, and responds with its SVG diagram. A request that is not a
+// POST is answered with 405 Method Not Allowed, and one whose body cannot
+// be read or parsed with 400 Bad Request and the error.
+func SVGHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "a VCD file must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		svg, err := SvgFromBytes(content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write(svg)
+	})
+}
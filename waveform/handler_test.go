@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSVGHandler(t *testing.T) {
+	content, err := os.ReadFile("../example/blinky.vcd")
+	require.NoError(t, err)
+	expected, err := SvgFromBytes(content)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(SVGHandler())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "text/plain", bytes.NewReader(content))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/svg+xml", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, expected, body)
+}
+
+func TestSVGHandler_Errors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	SVGHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not a vcd $end")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "parse error")
+
+	rec = httptest.NewRecorder()
+	SVGHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+}
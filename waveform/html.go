@@ -0,0 +1,247 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// htmlTemplate is the page used by DrawHTML, the SVGs are placed inside the
+// body followed by the popup used to show the bits of a bus segment, and
+// the value changes read by the cursor readout.
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { margin: 0; font-family: monospace; }
+.bus-segment:hover { fill-opacity: 0.8; }
+.pinned { position: sticky; top: 0; z-index: 1; }
+#cursor { position: fixed; top: 0; bottom: 0; width: 1px; display: none; background: #ffff00; pointer-events: none; }
+#cursor-readout { position: fixed; top: 8px; display: none; padding: 4px 6px; background: #202020; color: #ffffff; border: 1px solid #888; font-size: 12px; white-space: pre; pointer-events: none; }
+#bit-popup { position: fixed; display: none; padding: 4px 6px; background: #ffffe0; border: 1px solid #888; font-size: 12px; white-space: pre; pointer-events: none; }
+</style>
+</head>
+<body>
+%s
+<div id="bit-popup"></div>
+<div id="cursor"></div>
+<div id="cursor-readout"></div>
+<script id="waveform-data" type="application/json">%s</script>
+<script>
+(function() {
+  var popup = document.getElementById("bit-popup");
+  document.querySelectorAll(".bus-segment").forEach(function(segment) {
+    segment.addEventListener("mousemove", function(e) {
+      var bits = segment.dataset.bits;
+      var msb = parseInt(segment.dataset.msb, 10);
+      var lsb = parseInt(segment.dataset.lsb, 10);
+      var step = msb >= lsb ? -1 : 1;
+      var lines = [segment.dataset.signal + " [" + msb + ":" + lsb + "]"];
+      for (var i = 0; i < bits.length; i++) {
+        lines.push("bit " + (msb + i * step) + ": " + bits[i]);
+      }
+      popup.textContent = lines.join("\n");
+      popup.style.left = (e.clientX + 12) + "px";
+      popup.style.top = (e.clientY + 12) + "px";
+      popup.style.display = "block";
+    });
+    segment.addEventListener("mouseleave", function() {
+      popup.style.display = "none";
+    });
+  });
+})();
+(function() {
+  var data = JSON.parse(document.getElementById("waveform-data").textContent);
+  var cursor = document.getElementById("cursor");
+  var readout = document.getElementById("cursor-readout");
+  function valueAt(signal, t) {
+    var value = "-";
+    for (var i = 0; i < signal.times.length && signal.times[i] <= t; i++) {
+      value = signal.values[i];
+    }
+    return value;
+  }
+  function hide() {
+    cursor.style.display = "none";
+    readout.style.display = "none";
+  }
+  document.querySelectorAll("svg").forEach(function(diagram) {
+    diagram.addEventListener("mousemove", function(e) {
+      var box = diagram.getBoundingClientRect();
+      var column = Math.round((e.clientX - box.left - data.origin) / data.step);
+      if (column < 0 || column >= data.times.length) {
+        hide();
+        return;
+      }
+      var t = data.times[column];
+      var lines = ["t = " + data.labels[column]];
+      data.signals.forEach(function(signal) {
+        lines.push(signal.name + " = " + valueAt(signal, t));
+      });
+      var x = box.left + data.origin + column * data.step;
+      cursor.style.left = x + "px";
+      cursor.style.display = "block";
+      readout.textContent = lines.join("\n");
+      readout.style.left = (x + 8) + "px";
+      readout.style.display = "block";
+    });
+    diagram.addEventListener("mouseleave", hide);
+  });
+})();
+</script>
+</body>
+</html>
+`
+
+// DrawHTML generates an interactive HTML page holding the SVG waveform
+// visualization of the simulation data. Hovering over a bus segment shows
+// the value of each of its bits, and moving over a diagram places a cursor
+// with a readout of every signal's value at that time. The PinnedSignals are drawn in a separate
+// diagram, along with the time axis, that stays at the top of the page
+// while the rest of the signals are scrolled.
+func DrawHTML(vcdData *VcdData, opts RenderOptions) []byte {
+	vcdData = prepareData(vcdData, opts)
+
+	pinned, rest := *vcdData, *vcdData
+	pinned.Signals, rest.Signals = nil, nil
+	for _, sig := range vcdData.Signals {
+		if slices.Contains(opts.PinnedSignals, sig) {
+			pinned.Signals = append(pinned.Signals, sig)
+		} else {
+			rest.Signals = append(rest.Signals, sig)
+		}
+	}
+	data := cursorReadoutData(vcdData, opts)
+	if len(pinned.Signals) == 0 {
+		return fmt.Appendf(nil, htmlTemplate, "Waveform", drawSVG(vcdData, opts, true), data)
+	}
+
+	body := fmt.Sprintf("<div class=\"pinned\">\n%s</div>\n<div class=\"signals\">\n%s</div>",
+		drawSVG(&pinned, opts, true), drawSVG(&rest, opts, true))
+	return fmt.Appendf(nil, htmlTemplate, "Waveform", body, data)
+}
+
+// cursorData is the layout and value changes embedded in the HTML export,
+// from which the cursor readout finds each signal's value under the cursor.
+type cursorData struct {
+	// Origin is the x coordinate of the first column within each diagram,
+	// and Step the width of a column
+	Origin int `json:"origin"`
+	Step   int `json:"step"`
+
+	// Times holds the time of each column, and Labels the time shown for
+	// it, formatted like the labels of the time axis
+	Times  []uint64 `json:"times"`
+	Labels []string `json:"labels"`
+
+	Signals []cursorSignal `json:"signals"`
+}
+
+// cursorSignal holds the times at which a signal changed and the value it
+// changed to at each.
+type cursorSignal struct {
+	Name   string   `json:"name"`
+	Times  []uint64 `json:"times"`
+	Values []string `json:"values"`
+}
+
+// cursorReadoutData encodes the value changes of every signal for the
+// cursor readout. The encoding escapes "<" and ">", so the result can be
+// placed inside a script element.
+func cursorReadoutData(vcdData *VcdData, opts RenderOptions) []byte {
+	r := newRenderer(nil, vcdData, opts)
+	data := cursorData{
+		Origin:  r.layout.leftMargin,
+		Step:    r.layout.stepWidth,
+		Times:   append([]uint64{}, vcdData.Times...),
+		Labels:  []string{},
+		Signals: []cursorSignal{},
+	}
+	for _, t := range vcdData.Times {
+		data.Labels = append(data.Labels, r.timeLabel(t))
+	}
+	if opts.Sidebar != nil && opts.Sidebar.Left {
+		data.Origin += sidebarWidth
+	}
+
+	changes := vcdData.Changes
+	for _, sig := range vcdData.Signals {
+		signal := cursorSignal{Name: sig, Times: []uint64{}, Values: []string{}}
+		for _, change := range changes[sig] {
+			signal.Times = append(signal.Times, change.Time)
+			signal.Values = append(signal.Values, change.Value)
+		}
+		data.Signals = append(data.Signals, signal)
+	}
+
+	encoded, _ := json.Marshal(data)
+	return encoded
+}
+
+// busSegmentAttrs returns the attributes of the fill of a bus segment. In
+// the HTML export the segment also carries the signal name, the indices of
+// its leftmost and rightmost bits and the value of each bit, most
+// significant bit first.
+func (r *renderer) busSegmentAttrs(sig, val, style string) []string {
+	if !r.interactive || isRealValue(val) {
+		return []string{style}
+	}
+
+	width := max(r.vcdData.Widths[sig], len(val))
+	bits := r.busBitRange(sig, width)
+	return []string{
+		style,
+		`class="bus-segment"`,
+		fmt.Sprintf(`data-signal="%s"`, html.EscapeString(sig)),
+		fmt.Sprintf(`data-msb="%d" data-lsb="%d"`, bits.MSB, bits.LSB),
+		fmt.Sprintf(`data-bits="%s"`, html.EscapeString(busBits(val, width))),
+	}
+}
+
+// busBitRange returns the indices of the leftmost and rightmost bits of the
+// values of sig as drawn, i.e. after the BitOrder put the most significant
+// bit first. They follow the declared range of the bus, or [width-1:0] for
+// a bus declared without one.
+func (r *renderer) busBitRange(sig string, width int) BitRange {
+	bits, ok := r.vcdData.Ranges[sig]
+	if !ok || max(bits.MSB-bits.LSB, bits.LSB-bits.MSB)+1 != width {
+		bits = BitRange{MSB: width - 1, LSB: 0}
+	}
+	if r.vcdData.lsbFirst(sig, r.opts.BitOrder) {
+		bits.MSB, bits.LSB = bits.LSB, bits.MSB
+	}
+	return bits
+}
+
+// busBits extends a bus value to the given number of bits. As in a VCD
+// value change, the value is padded with "0" unless its leftmost bit is
+// "x" or "z", in which case that bit is repeated.
+func busBits(val string, width int) string {
+	if len(val) >= width {
+		return val
+	}
+	pad := "0"
+	if val != "" && strings.ContainsAny(val[:1], "xXzZ") {
+		pad = val[:1]
+	}
+	return strings.Repeat(pad, width-len(val)) + val
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawHTML_BusBitDetail(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "bus"},
+		Widths:  map[string]int{"clk": 1, "bus": 8},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "bus": "101"},
+		1: {"clk": "1", "bus": "101"},
+		2: {"clk": "0", "bus": "x"},
+		3: {"clk": "1", "bus": "x"},
+	})
+
+	htmlStr := string(DrawHTML(vcdData, RenderOptions{}))
+	assert.True(t, strings.HasPrefix(htmlStr, "<!DOCTYPE html>"))
+	assert.Contains(t, htmlStr, `class="bus-segment" data-signal="bus" data-msb="7" data-lsb="0" data-bits="00000101"`)
+	assert.Contains(t, htmlStr, `data-bits="xxxxxxxx"`)
+	assert.Contains(t, htmlStr, "<script>")
+
+	// the plain SVG carries no hover data
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.NotContains(t, svgStr, "data-bits")
+}
+
+func TestDrawHTML_BusBitIndices(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"down", "up"},
+		Widths:  map[string]int{"down": 4, "up": 4},
+		Ranges:  map[string]BitRange{"down": {MSB: 11, LSB: 8}, "up": {MSB: 0, LSB: 3}},
+	}, map[uint64]map[string]string{
+		0: {"down": "0001", "up": "0001"},
+		1: {"down": "0011", "up": "0011"},
+	})
+
+	// an ascending bus is read LSB-first, so its bits are drawn reversed
+	htmlStr := string(DrawHTML(vcdData, RenderOptions{}))
+	assert.Contains(t, htmlStr, `data-signal="down" data-msb="11" data-lsb="8" data-bits="0001"`)
+	assert.Contains(t, htmlStr, `data-signal="up" data-msb="3" data-lsb="0" data-bits="1000"`)
+
+	htmlStr = string(DrawHTML(vcdData, RenderOptions{BitOrder: BitOrderMSBFirst}))
+	assert.Contains(t, htmlStr, `data-signal="up" data-msb="0" data-lsb="3" data-bits="0001"`)
+
+	htmlStr = string(DrawHTML(vcdData, RenderOptions{BitOrder: BitOrderLSBFirst}))
+	assert.Contains(t, htmlStr, `data-signal="down" data-msb="8" data-lsb="11" data-bits="1000"`)
+
+	// the bits of a grouped bus are numbered by their names
+	bitData := withSnapshots(&VcdData{
+		Signals: []string{"data[4]", "data[5]"},
+	}, map[uint64]map[string]string{
+		0: {"data[4]": "1", "data[5]": "0"},
+		1: {"data[4]": "1", "data[5]": "0"},
+	})
+	htmlStr = string(DrawHTML(bitData, RenderOptions{AutoGroupBitSignals: true}))
+	assert.Contains(t, htmlStr, `data-signal="data" data-msb="5" data-lsb="4" data-bits="01"`)
+}
+
+func TestBusBits(t *testing.T) {
+	assert.Equal(t, "0011", busBits("11", 4))
+	assert.Equal(t, "zzz1", busBits("z1", 4))
+	assert.Equal(t, "1010", busBits("1010", 2))
+}
+
+func TestDrawHTML_PinnedSignals(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b", "clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "a": "0", "b": "1"},
+		1: {"clk": "1", "a": "1", "b": "0"},
+	})
+
+	plain := string(DrawHTML(vcdData, RenderOptions{}))
+	assert.NotContains(t, plain, `<div class="pinned">`)
+
+	htmlStr := string(DrawHTML(vcdData, RenderOptions{PinnedSignals: []string{"clk"}}))
+	assert.Contains(t, htmlStr, ".pinned { position: sticky; top: 0;")
+
+	// the clock is drawn in the sticky section, and only there
+	pinned := htmlStr[strings.Index(htmlStr, `<div class="pinned">`):strings.Index(htmlStr, `<div class="signals">`)]
+	rest := htmlStr[strings.Index(htmlStr, `<div class="signals">`):]
+	assert.Contains(t, pinned, `<g id="signal-clk">`)
+	assert.NotContains(t, pinned, `<g id="signal-a">`)
+	assert.Contains(t, rest, `<g id="signal-a">`)
+	assert.Contains(t, rest, `<g id="signal-b">`)
+	assert.NotContains(t, rest, `<g id="signal-clk">`)
+}
+
+func TestDrawHTML_CursorReadout(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus", "clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "bus": "0001"},
+		1: {"clk": "1", "bus": "0001"},
+		2: {"clk": "0", "bus": "<10>"},
+	})
+
+	htmlStr := string(DrawHTML(vcdData, RenderOptions{TimeOffset: 100}))
+	assert.Contains(t, htmlStr, `<div id="cursor"></div>`)
+	assert.Contains(t, htmlStr, `<div id="cursor-readout"></div>`)
+	assert.Contains(t, htmlStr, "function valueAt(signal, t)")
+
+	// the value changes are embedded as JSON, with markup escaped
+	start := strings.Index(htmlStr, `<script id="waveform-data" type="application/json">`)
+	assert.NotEqual(t, -1, start)
+	data := htmlStr[start:]
+	data = data[strings.Index(data, ">")+1 : strings.Index(data, "</script>")]
+	assert.Equal(t, `{"origin":150,"step":20,"times":[0,1,2],"labels":["100","101","102"],"signals":[`+
+		`{"name":"bus","times":[0,2],"values":["0001","\u003c10\u003e"]},`+
+		`{"name":"clk","times":[0,1,2],"values":["0","1","0"]}]}`, data)
+
+	// a sidebar on the left moves the origin of the diagram
+	htmlStr = string(DrawHTML(vcdData, RenderOptions{Sidebar: &Sidebar{Left: true}}))
+	assert.Contains(t, htmlStr, `{"origin":310,`)
+
+	// the time shown is rounded like the labels of the time axis
+	vcdData.Timescale = Timescale{Magnitude: 1, Unit: "ns"}
+	htmlStr = string(DrawHTML(vcdData, RenderOptions{
+		TimeOffset:   1234,
+		TimeRounding: TimeRounding{Mode: RoundSignificant, Digits: 2},
+	}))
+	assert.Contains(t, htmlStr, `"labels":["1200ns","1200ns","1200ns"]`)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+)
+
+// jsonStep is the JSON representation of the signal values at a single time step.
+type jsonStep struct {
+	Time   uint64            `json:"time"`
+	Values map[string]string `json:"values"`
+}
+
+// jsonDocument is the JSON representation of a VcdData structure. The
+// simulation steps are emitted as an array ordered by time.
+type jsonDocument struct {
+	Signals []string            `json:"signals"`
+	Decl    map[string][]string `json:"decl"`
+	Sim     []jsonStep          `json:"sim"`
+}
+
+// ToJSON serializes the VcdData into a JSON document containing the signals,
+// the declarations and the simulation steps ordered by time.
+func ToJSON(vcdData *VcdData) ([]byte, error) {
+	doc := jsonDocument{
+		Signals: vcdData.Signals,
+		Decl:    vcdData.Decl,
+		Sim:     make([]jsonStep, 0, len(vcdData.Times)),
+	}
+	for t, step := range vcdData.snapshots() {
+		doc.Sim = append(doc.Sim, jsonStep{Time: t, Values: maps.Clone(step)})
+	}
+	return json.Marshal(doc)
+}
+
+// StreamJSON writes the same JSON document as ToJSON to the provided writer,
+// encoding one simulation step at a time from the changes of the signals,
+// so that neither the document nor the values at every time are ever held
+// in memory. Errors from the writer are returned to the caller.
+func StreamJSON(w io.Writer, vcdData *VcdData) error {
+	signals, err := json.Marshal(vcdData.Signals)
+	if err != nil {
+		return fmt.Errorf("could not encode signals: %w", err)
+	}
+	decl, err := json.Marshal(vcdData.Decl)
+	if err != nil {
+		return fmt.Errorf("could not encode declarations: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, `{"signals":%s,"decl":%s,"sim":[`, signals, decl); err != nil {
+		return err
+	}
+
+	first := true
+	for t, values := range vcdData.snapshots() {
+		step, err := json.Marshal(jsonStep{Time: t, Values: values})
+		if err != nil {
+			return fmt.Errorf("could not encode time step %d: %w", t, err)
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(step); err != nil {
+			return err
+		}
+		first = false
+	}
+
+	_, err = io.WriteString(w, "]}")
+	return err
+}
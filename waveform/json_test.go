@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamJSON_MatchesToJSON(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"bus"},
+		},
+		Signals: []string{"bus", "clk"},
+	}, map[uint64]map[string]string{
+		0:  {"clk": "0", "bus": "1010"},
+		2:  {"clk": "1", "bus": "1010"},
+		10: {"clk": "0", "bus": "1111"},
+	})
+
+	var buf bytes.Buffer
+	if err := StreamJSON(&buf, vcdData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.True(t, json.Valid(buf.Bytes()))
+
+	expected, err := ToJSON(vcdData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, string(expected), buf.String())
+
+	var doc jsonDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Len(t, doc.Sim, 3)
+	assert.Equal(t, uint64(10), doc.Sim[2].Time)
+	assert.Equal(t, "1111", doc.Sim[2].Values["bus"])
+}
+
+func TestStreamJSON_WriteError(t *testing.T) {
+	vcdData := scalarSignals(3, 20)
+
+	var buf bytes.Buffer
+	assert.NoError(t, StreamJSON(&buf, vcdData))
+
+	// the error of the writer is returned wherever the document is cut off
+	for _, remaining := range []int{0, 10, buf.Len() / 2, buf.Len() - 1} {
+		assert.EqualError(t, StreamJSON(&failingWriter{remaining: remaining}, vcdData), "disk full")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeVcdData combines two traces into one, so that the signals of both
+// are drawn in a single diagram. The names of the signals of each trace
+// are prefixed with the given prefix, as an outer scope, and the signals of
+// a are drawn before those of b. The traces are aligned by their raw
+// simulation times, with each signal holding its value over the times that
+// only the other trace records. An error is returned if the prefixed names
+// collide or the traces declare different timescales.
+func MergeVcdData(a, b *VcdData, prefixA, prefixB string) (*VcdData, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("cannot merge a missing trace")
+	}
+
+	merged := &VcdData{
+		Changes:   map[string][]Change{},
+		Decl:      map[string][]string{},
+		Scopes:    map[string][]string{},
+		Widths:    map[string]int{},
+		Ranges:    map[string]BitRange{},
+		Types:     map[string]string{},
+		Timescale: a.Timescale,
+	}
+	if a.Timescale == (Timescale{}) {
+		merged.Timescale = b.Timescale
+	} else if b.Timescale != (Timescale{}) && a.Timescale != b.Timescale {
+		return nil, fmt.Errorf("cannot merge traces with timescales of %s and %s", a.Timescale, b.Timescale)
+	}
+
+	seen := map[string]bool{}
+	renames := make([]map[string]string, 2)
+	for i, side := range []struct {
+		data   *VcdData
+		prefix string
+	}{{a, prefixA}, {b, prefixB}} {
+		rename := map[string]string{}
+		for _, sig := range side.data.Signals {
+			name := prefixName(side.prefix, sig)
+			if seen[name] {
+				return nil, fmt.Errorf("signal %q is present in both traces, use distinct prefixes", name)
+			}
+			seen[name] = true
+			rename[sig] = name
+			merged.Signals = append(merged.Signals, name)
+
+			path := slices.Clone(side.data.Scopes[sig])
+			if side.prefix != "" {
+				path = append([]string{side.prefix}, path...)
+			}
+			if len(path) > 0 {
+				merged.Scopes[name] = path
+			}
+			if width, ok := side.data.Widths[sig]; ok {
+				merged.Widths[name] = width
+			}
+			if r, ok := side.data.Ranges[sig]; ok {
+				merged.Ranges[name] = r
+			}
+			if typ, ok := side.data.Types[sig]; ok {
+				merged.Types[name] = typ
+			}
+			if dir, ok := side.data.Directions[sig]; ok {
+				if merged.Directions == nil {
+					merged.Directions = map[string]PortDirection{}
+				}
+				merged.Directions[name] = dir
+			}
+		}
+		for _, sig := range side.data.DeclOrder {
+			if name, ok := rename[sig]; ok {
+				merged.DeclOrder = append(merged.DeclOrder, name)
+			}
+		}
+		for code, sigs := range side.data.Decl {
+			var names []string
+			for _, sig := range sigs {
+				if name, ok := rename[sig]; ok {
+					names = append(names, name)
+				}
+			}
+			if len(names) == 0 {
+				continue
+			}
+			// the codes are only used while parsing, so a clash with a
+			// code of the first trace is resolved by marking it
+			code = prefixName(side.prefix, code)
+			for merged.Decl[code] != nil {
+				code += "'"
+			}
+			merged.Decl[code] = names
+		}
+		renames[i] = rename
+	}
+
+	// the signals keep their changes, and hold their values over the
+	// times of the other trace
+	for i, data := range []*VcdData{a, b} {
+		for sig, changes := range data.Changes {
+			if name, ok := renames[i][sig]; ok {
+				merged.Changes[name] = changes
+			}
+		}
+	}
+	merged.Times = mergedTimes(a.Times, b.Times)
+	return merged, nil
+}
+
+// prefixName returns the name placed within the scope named by prefix, or
+// the name itself when the prefix is empty.
+func prefixName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + " " + name
+}
+
+// mergedTimes returns the union of the times of both simulations, in
+// ascending order.
+func mergedTimes(a, b []uint64) []uint64 {
+	times := slices.Concat(a, b)
+	slices.Sort(times)
+	return slices.Compact(times)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const referenceVcd = `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 4 " data $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+b0001 "
+#5
+1!
+b0010 "
+`
+
+func TestMergeVcdData(t *testing.T) {
+	a, err := ParseVCD(bytes.NewReader([]byte(sparseVcd)), "sparse.vcd")
+	require.NoError(t, err)
+	b, err := ParseVCD(bytes.NewReader([]byte(referenceVcd)), "reference.vcd")
+	require.NoError(t, err)
+
+	merged, err := MergeVcdData(a, b, "dut", "ref")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dut test clk", "dut test data", "ref test clk", "ref test data"}, merged.Signals)
+	assert.Equal(t, []string{"dut", "test"}, merged.Scopes["dut test clk"])
+	assert.Equal(t, []string{"ref", "test"}, merged.Scopes["ref test clk"])
+	assert.Equal(t, 4, merged.Widths["dut test data"])
+	assert.Len(t, merged.Decl, 4)
+	assert.Equal(t, a.Timescale, merged.Timescale)
+
+	// the times of both traces are kept, each holding its values between
+	// its own changes
+	assert.Equal(t, []uint64{0, 3, 5, 10}, merged.Times)
+	assert.Equal(t, map[string]string{
+		"dut test clk": "1", "dut test data": "0011",
+		"ref test clk": "1", "ref test data": "0010",
+	}, merged.SnapshotAt(5))
+	assert.Equal(t, "0001", merged.Value("ref test data", 3))
+
+	// the merged trace draws both sets of signals
+	svgStr := string(DrawSVGWithOptions(merged, RenderOptions{ScopeDisplay: ScopeFull}))
+	assert.Contains(t, svgStr, ">dut test clk</text>")
+	assert.Contains(t, svgStr, ">ref test data</text>")
+}
+
+func TestMergeVcdData_Errors(t *testing.T) {
+	a, err := ParseVCD(bytes.NewReader([]byte(sparseVcd)), "sparse.vcd")
+	require.NoError(t, err)
+
+	_, err = MergeVcdData(a, a, "", "")
+	assert.EqualError(t, err, `signal "test clk" is present in both traces, use distinct prefixes`)
+
+	b := *a
+	b.Timescale = Timescale{Magnitude: 10, Unit: "ps"}
+	_, err = MergeVcdData(a, &b, "a", "b")
+	assert.EqualError(t, err, "cannot merge traces with timescales of 1ns and 10ps")
+
+	_, err = MergeVcdData(a, nil, "a", "b")
+	assert.Error(t, err)
+}
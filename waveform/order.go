@@ -0,0 +1,71 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SignalOrder selects the order in which the signals are drawn.
+type SignalOrder int
+
+const (
+	// SignalOrderAlphabetical draws the signals sorted by name.
+	SignalOrderAlphabetical SignalOrder = iota
+	// SignalOrderDeclared draws the signals in the order their $var
+	// commands appear in the VCD file.
+	SignalOrderDeclared
+	// SignalOrderCustom draws the signals in the order listed in
+	// RenderOptions.CustomSignalOrder.
+	SignalOrderCustom
+)
+
+// SignalOrderNames lists the names of the orders accepted by
+// SignalOrderByName.
+var SignalOrderNames = []string{"alphabetical", "declared"}
+
+// SignalOrderByName returns the signal order with the given name. The
+// custom order needs a list of signals, so it has no name.
+func SignalOrderByName(name string) (SignalOrder, error) {
+	switch strings.ToLower(name) {
+	case "alphabetical":
+		return SignalOrderAlphabetical, nil
+	case "declared":
+		return SignalOrderDeclared, nil
+	}
+	return 0, fmt.Errorf("unknown signal order %q (expected one of %s)", name, strings.Join(SignalOrderNames, ", "))
+}
+
+// OrderSignals returns a copy of the VcdData with its signals reordered to
+// follow the given names. Signals that are not named keep their relative
+// order after those that are, and names of missing signals are ignored.
+func (v *VcdData) OrderSignals(order []string) *VcdData {
+	ordered := *v
+	ordered.Signals = make([]string, 0, len(v.Signals))
+	for _, name := range order {
+		if slices.Contains(v.Signals, name) && !slices.Contains(ordered.Signals, name) {
+			ordered.Signals = append(ordered.Signals, name)
+		}
+	}
+	for _, sig := range v.Signals {
+		if !slices.Contains(ordered.Signals, sig) {
+			ordered.Signals = append(ordered.Signals, sig)
+		}
+	}
+	return &ordered
+}
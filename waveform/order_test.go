@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const declOrderVcd = `$timescale 1ns $end
+$var wire 1 ! zeta $end
+$var wire 1 " alpha $end
+$var wire 1 # mid $end
+$enddefinitions $end
+#0
+0!
+1"
+0#
+#1
+1!
+`
+
+func TestProcessVcd_DeclOrder(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(declOrderVcd)), "order.vcd")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"alpha", "mid", "zeta"}, vcdData.Signals)
+	assert.Equal(t, []string{"zeta", "alpha", "mid"}, vcdData.DeclOrder)
+}
+
+func TestVcdData_OrderSignals(t *testing.T) {
+	vcdData := &VcdData{Signals: []string{"a", "b", "c", "d"}}
+
+	// unlisted signals follow in their existing order, unknown names are
+	// ignored
+	ordered := vcdData.OrderSignals([]string{"c", "missing", "a", "c"})
+	assert.Equal(t, []string{"c", "a", "b", "d"}, ordered.Signals)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, vcdData.Signals)
+}
+
+func TestDrawSVGWithOptions_SignalOrder(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(declOrderVcd)), "order.vcd")
+	require.NoError(t, err)
+
+	// the names of the signals, in the order their labels are drawn
+	labelOrder := func(opts RenderOptions) []string {
+		out := string(DrawSVGWithOptions(vcdData, opts))
+		names := []string{"alpha", "mid", "zeta"}
+		slices.SortFunc(names, func(a, b string) int {
+			return strings.Index(out, ">"+a+"</text>") - strings.Index(out, ">"+b+"</text>")
+		})
+		return names
+	}
+
+	assert.Equal(t, []string{"alpha", "mid", "zeta"}, labelOrder(RenderOptions{}))
+	assert.Equal(t, []string{"zeta", "alpha", "mid"}, labelOrder(RenderOptions{SignalOrder: SignalOrderDeclared}))
+	assert.Equal(t, []string{"mid", "alpha", "zeta"},
+		labelOrder(RenderOptions{SignalOrder: SignalOrderCustom, CustomSignalOrder: []string{"mid"}}))
+}
+
+func TestSignalOrderByName(t *testing.T) {
+	order, err := SignalOrderByName("Declared")
+	require.NoError(t, err)
+	assert.Equal(t, SignalOrderDeclared, order)
+
+	_, err = SignalOrderByName("random")
+	assert.EqualError(t, err, `unknown signal order "random" (expected one of alphabetical, declared)`)
+}
+
+func TestVcdData_GroupBitSignals_DeclOrder(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals:   []string{"d[0]", "d[1]", "z"},
+		DeclOrder: []string{"z", "d[1]", "d[0]"},
+	}, map[uint64]map[string]string{0: {"z": "0", "d[1]": "1", "d[0]": "0"}})
+
+	grouped := vcdData.GroupBitSignals()
+	assert.Equal(t, []string{"z", "d"}, grouped.DeclOrder)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+
+	svg "github.com/ajstarks/svgo"
+)
+
+// Window returns the part of the simulation between the start and end
+// times, inclusive, shifted so that the window starts at time zero. The
+// values in effect at the start and end of the window are recorded at
+// its first and last times, so segments crossing either edge are drawn up
+// to it.
+func (v *VcdData) Window(start, end uint64) *VcdData {
+	window := v.Clip(start, end)
+	for i := range window.Times {
+		window.Times[i] -= start
+	}
+	for _, changes := range window.Changes {
+		for i := range changes {
+			changes[i].Time -= start
+		}
+	}
+	return window
+}
+
+// Clip returns the part of the simulation between the start and end times,
+// inclusive, like Window but keeping the original times.
+func (v *VcdData) Clip(start, end uint64) *VcdData {
+	clipped := *v
+	clipped.Times = []uint64{start}
+	for _, t := range v.Times {
+		if t > start && t <= end {
+			clipped.Times = append(clipped.Times, t)
+		}
+	}
+	if end > start && clipped.Times[len(clipped.Times)-1] != end {
+		clipped.Times = append(clipped.Times, end)
+	}
+
+	clipped.Changes = make(map[string][]Change, len(v.Changes))
+	for sig, changes := range v.Changes {
+		var kept []Change
+		if val, ok := ValueAt(changes, start); ok {
+			kept = append(kept, Change{Time: start, Value: val})
+		}
+		for _, c := range changes {
+			if c.Time > start && c.Time <= end {
+				kept = appendChange(kept, c.Time, c.Value)
+			}
+		}
+		if len(kept) > 0 {
+			clipped.Changes[sig] = kept
+		}
+	}
+	return &clipped
+}
+
+// DrawSVGPages splits the diagram into pages of PageLength time units,
+// each drawn as its own SVG. Adjacent pages share the time at which one
+// ends and the next begins, and the labels of the pages after the first
+// show the original times. A bus segment crossing a page boundary has its
+// label repeated at the start of the next page. Without a PageLength the
+// whole diagram is drawn on a single page.
+func DrawSVGPages(vcdData *VcdData, opts RenderOptions) [][]byte {
+	vcdData = prepareData(vcdData, opts)
+	times := vcdData.Times
+	if opts.PageLength == 0 || len(times) == 0 {
+		return [][]byte{drawSVG(vcdData, opts, false)}
+	}
+
+	var pages [][]byte
+	first, last := times[0], times[len(times)-1]
+	for start := first; start == first || start < last; start += opts.PageLength {
+		end := min(start+opts.PageLength, last)
+		pageOpts := opts
+		pageOpts.TimeOffset += start
+		pages = append(pages, drawPage(vcdData.Window(start, end), pageOpts, start > first))
+	}
+	return pages
+}
+
+// DrawSVGSignalPages splits the diagram into pages of MaxSignalsPerPage
+// signals, each drawn as its own SVG. Every page covers the whole time
+// range, so the pages share the same time axis and can be stacked.
+// Without a MaxSignalsPerPage all the signals are drawn on a single page.
+func DrawSVGSignalPages(vcdData *VcdData, opts RenderOptions) [][]byte {
+	vcdData = prepareData(vcdData, opts)
+	if opts.MaxSignalsPerPage <= 0 || len(vcdData.Signals) <= opts.MaxSignalsPerPage {
+		return [][]byte{drawSVG(vcdData, opts, false)}
+	}
+
+	var pages [][]byte
+	for chunk := range slices.Chunk(vcdData.Signals, opts.MaxSignalsPerPage) {
+		pages = append(pages, drawSVG(vcdData.Filter(chunk), opts, false))
+	}
+	return pages
+}
+
+// drawPage renders one page of a paginated diagram, marking its values as
+// carried over from the previous page when continued is set.
+func drawPage(vcdData *VcdData, opts RenderOptions, continued bool) []byte {
+	var out bytes.Buffer
+	outputBuffer := bufio.NewWriter(&out)
+
+	r := newRenderer(svg.New(outputBuffer), vcdData, opts)
+	r.continued = continued
+	r.draw()
+
+	outputBuffer.Flush()
+	return out.Bytes()
+}
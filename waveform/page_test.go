@@ -0,0 +1,144 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGPages(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{Signals: []string{"clk", "data"}}
+	for i := range 13 {
+		data := "0001"
+		if i >= 6 {
+			data = "0010"
+		}
+		sim[uint64(i)] = map[string]string{"clk": fmt.Sprint(i % 2), "data": data}
+	}
+	vcdData.SetSnapshots(sim)
+
+	pages := DrawSVGPages(vcdData, RenderOptions{PageLength: 5})
+	assert.Len(t, pages, 3)
+
+	// the segment holding 0001 crosses the first boundary, so its label is
+	// shown on both sides of the split even though it changes straight
+	// after it
+	label := `<text x="151" y="90" style="` + busValueStyle + `" >0001</text>`
+	assert.Contains(t, string(pages[0]), label)
+	assert.Contains(t, string(pages[1]), label)
+	assert.Equal(t, 1, strings.Count(string(pages[1]), ">0001</text>"))
+	assert.Contains(t, string(pages[1]), `style="`+tickTextStyle+`" >5</text>`)
+	assert.Contains(t, string(pages[1]), `style="`+tickTextStyle+`" >9</text>`)
+	assert.Contains(t, string(pages[1]), ">0010</text>")
+	assert.NotContains(t, string(pages[2]), ">0001</text>")
+	assert.Contains(t, string(pages[2]), ">0010</text>")
+
+	// without a page length the whole diagram is one page
+	single := DrawSVGPages(vcdData, RenderOptions{})
+	assert.Equal(t, [][]byte{DrawSVGWithOptions(vcdData, RenderOptions{})}, single)
+}
+
+func TestVcdData_Window(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b"},
+	}, map[uint64]map[string]string{
+		0: {"a": "0"},
+		4: {"a": "1", "b": "01"},
+		9: {"a": "0"},
+	})
+
+	assert.Equal(t, map[uint64]map[string]string{
+		0: {"a": "0"},
+		2: {"a": "1", "b": "01"},
+		5: {"a": "1", "b": "01"},
+	}, vcdData.Window(2, 7).Snapshots())
+}
+
+func TestVcdData_Clip(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b"},
+	}, map[uint64]map[string]string{
+		0: {"a": "0"},
+		4: {"a": "1", "b": "01"},
+		9: {"a": "0"},
+	})
+
+	assert.Equal(t, map[uint64]map[string]string{
+		2: {"a": "0"},
+		4: {"a": "1", "b": "01"},
+		7: {"a": "1", "b": "01"},
+	}, vcdData.Clip(2, 7).Snapshots())
+}
+
+func TestDrawSVGWithOptions_TimeWindow(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{Signals: []string{"clk", "count"}}
+	for i := range 31 {
+		sim[uint64(i)] = map[string]string{"clk": fmt.Sprint(i % 2), "count": fmt.Sprintf("%08b", i/4)}
+	}
+	vcdData.SetSnapshots(sim)
+
+	opts := RenderOptions{StartTime: 10, EndTime: 20}
+	svgStr := string(DrawSVGWithOptions(vcdData, opts))
+	assert.Equal(t, 6, strings.Count(svgStr, `style="`+tickTextStyle+`"`))
+	assert.Contains(t, svgStr, `<text x="150" y="30" style="`+tickTextStyle+`" >10</text>`)
+	assert.Contains(t, svgStr, `style="`+tickTextStyle+`" >20</text>`)
+	assert.NotContains(t, svgStr, `style="`+tickTextStyle+`" >9</text>`)
+	assert.NotContains(t, svgStr, `style="`+tickTextStyle+`" >21</text>`)
+
+	// the value in effect at the start of the window is drawn from it
+	assert.Contains(t, svgStr, `<text x="151" y="90" style="`+busValueStyle+`" >00000010</text>`)
+
+	// without an end the window runs to the end of the simulation
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{StartTime: 25}))
+	assert.Equal(t, 3, strings.Count(svgStr, `style="`+tickTextStyle+`"`))
+}
+
+func TestDrawSVGSignalPages(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{Signals: []string{"a", "b", "c", "d", "e"}}
+	for i := range 4 {
+		step := map[string]string{}
+		for _, sig := range vcdData.Signals {
+			step[sig] = fmt.Sprint(i % 2)
+		}
+		sim[uint64(i)] = step
+	}
+	vcdData.SetSnapshots(sim)
+
+	pages := DrawSVGSignalPages(vcdData, RenderOptions{MaxSignalsPerPage: 2})
+	assert.Len(t, pages, 3)
+	assert.Contains(t, string(pages[0]), ">a</text>")
+	assert.Contains(t, string(pages[0]), ">b</text>")
+	assert.NotContains(t, string(pages[0]), ">c</text>")
+	assert.Contains(t, string(pages[2]), ">e</text>")
+	assert.NotContains(t, string(pages[2]), ">d</text>")
+
+	// every page is as wide as the others and shows the same times
+	for _, page := range pages {
+		assert.Contains(t, string(page), `<svg width="240"`)
+		assert.Contains(t, string(page), `style="`+tickTextStyle+`" >2</text>`)
+	}
+
+	// without a limit the whole diagram is one page
+	single := DrawSVGSignalPages(vcdData, RenderOptions{})
+	assert.Equal(t, [][]byte{DrawSVGWithOptions(vcdData, RenderOptions{})}, single)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"strings"
+
+	svg "github.com/ajstarks/svgo"
+)
+
+// pathStyle is added to the style of a path so that it is only stroked.
+const pathStyle = "fill:none"
+
+// pen draws the line segments of a signal. By default each segment is its
+// own line element, while in path mode the segments are gathered into one
+// path per style, written when the pen is flushed.
+type pen struct {
+	canvas *svg.SVG
+	paths  bool
+
+	// shadow is the style of the shadow drawn by lineWithShadow, or empty
+	// for no shadow, and shadowOffset its distance from the line
+	shadow       string
+	shadowOffset int
+
+	styles []string
+	d      map[string]*strings.Builder
+	ends   map[string][2]int
+}
+
+// newPen returns a pen drawing on the canvas, gathering the segments into
+// paths when paths is set.
+func newPen(canvas *svg.SVG, paths bool) *pen {
+	return &pen{
+		canvas: canvas,
+		paths:  paths,
+
+		shadowOffset: 1,
+		d:            map[string]*strings.Builder{},
+		ends:         map[string][2]int{},
+	}
+}
+
+// line draws a line from (x0,y0) to (x1,y1). In path mode a segment that
+// starts where the previous one of the same style ended continues it.
+func (p *pen) line(x0, y0, x1, y1 int, style string) {
+	if !p.paths {
+		p.canvas.Line(x0, y0, x1, y1, style)
+		return
+	}
+
+	d, ok := p.d[style]
+	if !ok {
+		d = &strings.Builder{}
+		p.d[style] = d
+		p.styles = append(p.styles, style)
+	}
+	if !ok || p.ends[style] != [2]int{x0, y0} {
+		fmt.Fprintf(d, "M%d %d", x0, y0)
+	}
+	fmt.Fprintf(d, "L%d %d", x1, y1)
+	p.ends[style] = [2]int{x1, y1}
+}
+
+// lineWithShadow draws a line from (x0,y0) to (x1,y1) with a shadow effect.
+// It first draws the shadow, offset below a horizontal line or to the right
+// of any other, and then draws the main line using the specified style.
+func (p *pen) lineWithShadow(x0, y0, x1, y1 int, style string) {
+	if p.shadow != "" && y0 == y1 {
+		p.line(x0, y0+p.shadowOffset, x1, y1+p.shadowOffset, p.shadow)
+	} else if p.shadow != "" {
+		p.line(x0+p.shadowOffset, y0, x1+p.shadowOffset, y1, p.shadow)
+	}
+	p.line(x0, y0, x1, y1, style)
+}
+
+// flush writes the gathered paths in the order their styles were first
+// used, so that shadows remain beneath the lines they belong to.
+func (p *pen) flush() {
+	for _, style := range p.styles {
+		p.canvas.Path(p.d[style].String(), strings.TrimSuffix(style, ";")+";"+pathStyle)
+	}
+	p.styles = nil
+	clear(p.d)
+	clear(p.ends)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import "fmt"
+
+// Transform is a processing stage applied to parsed VCD data before it is
+// rendered. Transforms should return a new VcdData rather than modifying
+// their input.
+type Transform func(*VcdData) (*VcdData, error)
+
+// Pipeline applies an ordered list of transforms to parsed VCD data before
+// rendering it.
+type Pipeline struct {
+	Transforms []Transform
+}
+
+// NewPipeline returns a Pipeline that applies the given transforms in order.
+func NewPipeline(transforms ...Transform) *Pipeline {
+	return &Pipeline{Transforms: transforms}
+}
+
+// Apply runs each transform in order, feeding the output of one stage into
+// the next. It stops at the first transform that returns an error.
+func (p *Pipeline) Apply(vcdData *VcdData) (*VcdData, error) {
+	for i, transform := range p.Transforms {
+		out, err := transform(vcdData)
+		if err != nil {
+			return nil, fmt.Errorf("transform %d failed: %w", i, err)
+		}
+		vcdData = out
+	}
+	return vcdData, nil
+}
+
+// Render applies the pipeline and draws the result as an SVG using the
+// provided render options.
+func (p *Pipeline) Render(vcdData *VcdData, opts RenderOptions) ([]byte, error) {
+	out, err := p.Apply(vcdData)
+	if err != nil {
+		return nil, err
+	}
+	return DrawSVGWithOptions(out, opts), nil
+}
+
+// RenameTransform returns a Transform that renames signals according to the
+// provided mapping of current name to new name.
+func RenameTransform(mapping map[string]string) Transform {
+	return func(vcdData *VcdData) (*VcdData, error) {
+		return vcdData.Rename(mapping), nil
+	}
+}
+
+// ResampleTransform returns a Transform that snaps the simulation onto a
+// uniform grid of the given interval.
+func ResampleTransform(interval uint64) Transform {
+	return func(vcdData *VcdData) (*VcdData, error) {
+		if interval == 0 {
+			return nil, fmt.Errorf("resample interval must be greater than zero")
+		}
+		return vcdData.Resample(interval), nil
+	}
+}
+
+// WindowTransform returns a Transform that keeps only the part of the
+// simulation between the start and end times, inclusive, keeping the
+// original times.
+func WindowTransform(start, end uint64) Transform {
+	return func(vcdData *VcdData) (*VcdData, error) {
+		if end < start {
+			return nil, fmt.Errorf("window end %d is before its start %d", end, start)
+		}
+		return vcdData.Clip(start, end), nil
+	}
+}
+
+// FilterTransform returns a Transform that keeps only the named signals, in
+// the order given. Names that are not present in the data are skipped.
+func FilterTransform(names []string) Transform {
+	return func(vcdData *VcdData) (*VcdData, error) {
+		return vcdData.Filter(names), nil
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pipelineData() *VcdData {
+	return withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"n123"},
+			"$": {"rst"},
+		},
+		Signals: []string{"clk", "n123", "rst"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "n123": "0", "rst": "1"},
+		1: {"clk": "1", "n123": "1", "rst": "0"},
+	})
+}
+
+func TestPipeline_FilterThenRename(t *testing.T) {
+	pipeline := NewPipeline(
+		FilterTransform([]string{"n123", "clk", "missing"}),
+		RenameTransform(map[string]string{"n123": "data_valid"}),
+	)
+
+	out, err := pipeline.Apply(pipelineData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, []string{"data_valid", "clk"}, out.Signals)
+	assert.Equal(t, map[string]string{"clk": "1", "data_valid": "1"}, out.SnapshotAt(1))
+	assert.Equal(t, map[string][]string{"!": {"clk"}, "#": {"data_valid"}}, out.Decl)
+
+	svg, err := pipeline.Render(pipelineData(), RenderOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Contains(t, string(svg), ">data_valid</text>")
+	assert.NotContains(t, string(svg), "rst")
+}
+
+func TestPipeline_OrderMatters(t *testing.T) {
+	// renaming first means the filter no longer matches the original name
+	pipeline := NewPipeline(
+		RenameTransform(map[string]string{"n123": "data_valid"}),
+		FilterTransform([]string{"n123"}),
+	)
+
+	out, err := pipeline.Apply(pipelineData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Empty(t, out.Signals)
+}
+
+func TestPipeline_Error(t *testing.T) {
+	failing := func(*VcdData) (*VcdData, error) {
+		return nil, errors.New("boom")
+	}
+
+	_, err := NewPipeline(failing).Apply(pipelineData())
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestPipeline_ResampleRequiresInterval(t *testing.T) {
+	_, err := NewPipeline(ResampleTransform(0)).Apply(pipelineData())
+	assert.Error(t, err)
+
+	out, err := NewPipeline(ResampleTransform(1)).Apply(pipelineData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Len(t, out.Times, 2)
+}
+
+func TestPipeline_WindowThenFilter(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(10) {
+		sim[i] = map[string]string{"clk": fmt.Sprint(i % 2), "count": fmt.Sprintf("%04b", i)}
+	}
+	vcdData := withSnapshots(&VcdData{Signals: []string{"clk", "count"}}, sim)
+
+	out, err := NewPipeline(WindowTransform(3, 6), FilterTransform([]string{"count"})).Apply(vcdData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, []string{"count"}, out.Signals)
+	assert.Equal(t, []uint64{3, 4, 5, 6}, out.Times)
+	assert.Equal(t, []Change{{3, "0011"}, {4, "0100"}, {5, "0101"}, {6, "0110"}}, out.Changes["count"])
+
+	_, err = NewPipeline(WindowTransform(6, 3)).Apply(vcdData)
+	assert.ErrorContains(t, err, "window end 3 is before its start 6")
+}
@@ -0,0 +1,247 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// rgbaColor matches a fill or stroke given as an rgba() colour, which the
+// rasterizer does not read.
+var rgbaColor = regexp.MustCompile(`(fill|stroke):\s*rgba\(\s*(\d+)\s*,\s*(\d+)\s*,\s*(\d+)\s*,\s*([\d.]+)\s*\)`)
+
+// PngFromBytes parses VCD data provided as a byte slice and renders it as
+// a PNG image of the same diagram SvgFromBytes draws.
+func PngFromBytes(content []byte) ([]byte, error) {
+	svgBytes, err := SvgFromBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	return RasterizeSVG(svgBytes, 1)
+}
+
+// RasterizeSVG renders an SVG drawn by this package as a PNG image, with
+// every dimension multiplied by scale. A scale of zero or less renders the
+// image at its own size. The shapes are drawn with a pure Go rasterizer,
+// and the text in the Go Mono font.
+func RasterizeSVG(content []byte, scale float64) ([]byte, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, errors.New("could not rasterize an empty SVG")
+	}
+	if scale <= 0 {
+		scale = 1
+	}
+
+	// give rgba() colours as an rgb() colour and an opacity instead
+	content = rgbaColor.ReplaceAll(content, []byte("$1:rgb($2,$3,$4);$1-opacity:$5"))
+
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(content), oksvg.IgnoreErrorMode)
+	if err != nil {
+		return nil, fmt.Errorf("could not read SVG: %w", err)
+	}
+	if icon.ViewBox.W <= 0 || icon.ViewBox.H <= 0 {
+		return nil, errors.New("could not rasterize an SVG without a width and height")
+	}
+
+	w, h := int(icon.ViewBox.W*scale+0.5), int(icon.ViewBox.H*scale+0.5)
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	scanner := rasterx.NewScannerGV(w, h, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+
+	if err := drawSVGText(img, content, scale); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		return nil, fmt.Errorf("could not encode PNG: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// svgText is a text element of an SVG, placed at x, y once the translations
+// of the groups enclosing it are applied.
+type svgText struct {
+	x, y  float64
+	style string
+	text  strings.Builder
+}
+
+// drawSVGText draws the text elements of an SVG onto the image, which the
+// rasterizer leaves out. The tooltips held in title elements are skipped.
+func drawSVGText(img *image.RGBA, content []byte, scale float64) error {
+	faces := map[string]font.Face{}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var offsets [][2]float64
+	var text *svgText
+	inTitle := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read SVG text: %w", err)
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			dx, dy := 0.0, 0.0
+			if len(offsets) > 0 {
+				dx, dy = offsets[len(offsets)-1][0], offsets[len(offsets)-1][1]
+			}
+			switch el.Name.Local {
+			case "g":
+				if tx, ty, ok := translation(attr(el, "transform")); ok {
+					dx, dy = dx+tx, dy+ty
+				}
+			case "text":
+				x, _ := strconv.ParseFloat(attr(el, "x"), 64)
+				y, _ := strconv.ParseFloat(attr(el, "y"), 64)
+				text = &svgText{x: x + dx, y: y + dy, style: attr(el, "style")}
+			case "title":
+				inTitle = true
+			}
+			offsets = append(offsets, [2]float64{dx, dy})
+		case xml.CharData:
+			if text != nil && !inTitle {
+				text.text.Write(el)
+			}
+		case xml.EndElement:
+			offsets = offsets[:len(offsets)-1]
+			switch el.Name.Local {
+			case "title":
+				inTitle = false
+			case "text":
+				if err := drawText(img, text, scale, faces); err != nil {
+					return err
+				}
+				text = nil
+			}
+		}
+	}
+}
+
+// drawText draws a single text element, in the size, weight, colour and
+// alignment given by its style.
+func drawText(img *image.RGBA, text *svgText, scale float64, faces map[string]font.Face) error {
+	label := strings.TrimSpace(text.text.String())
+	if label == "" {
+		return nil
+	}
+
+	size, bold, anchor := 12.0, false, "start"
+	var fill color.Color = color.Black
+	for _, decl := range strings.Split(text.style, ";") {
+		key, value, _ := strings.Cut(decl, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "font-size":
+			if px, err := strconv.ParseFloat(strings.TrimSuffix(value, "px"), 64); err == nil {
+				size = px
+			}
+		case "font-weight":
+			bold = value == "bold"
+		case "text-anchor":
+			anchor = value
+		case "fill":
+			if c, err := oksvg.ParseSVGColor(value); err == nil && c != nil {
+				fill = c
+			}
+		}
+	}
+
+	key := fmt.Sprintf("%g/%t", size*scale, bold)
+	face, ok := faces[key]
+	if !ok {
+		ttf := gomono.TTF
+		if bold {
+			ttf = gomonobold.TTF
+		}
+		parsed, err := opentype.Parse(ttf)
+		if err != nil {
+			return fmt.Errorf("could not load font: %w", err)
+		}
+		face, err = opentype.NewFace(parsed, &opentype.FaceOptions{Size: size * scale, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return fmt.Errorf("could not load font: %w", err)
+		}
+		faces[key] = face
+	}
+
+	drawer := font.Drawer{Dst: img, Src: image.NewUniform(fill), Face: face}
+	x := text.x * scale
+	switch anchor {
+	case "middle":
+		x -= float64(drawer.MeasureString(label)) / 64 / 2
+	case "end":
+		x -= float64(drawer.MeasureString(label)) / 64
+	}
+	drawer.Dot = fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(text.y * scale * 64)}
+	drawer.DrawString(label)
+	return nil
+}
+
+// attr returns the value of the named attribute of an element.
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// translation reads the offset of a transform of the form
+// "translate(x,y)".
+func translation(transform string) (float64, float64, bool) {
+	args, ok := strings.CutPrefix(strings.TrimSpace(transform), "translate(")
+	if !ok {
+		return 0, 0, false
+	}
+	parts := strings.FieldsFunc(strings.TrimSuffix(args, ")"), func(r rune) bool { return r == ',' || r == ' ' })
+	if len(parts) == 0 {
+		return 0, 0, false
+	}
+	x, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	y := 0.0
+	if len(parts) > 1 {
+		y, _ = strconv.ParseFloat(parts[1], 64)
+	}
+	return x, y, true
+}
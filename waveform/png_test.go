@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPngFromBytes(t *testing.T) {
+	content, err := os.ReadFile("../example/blinky.vcd")
+	require.NoError(t, err)
+
+	pngBytes, err := PngFromBytes(content)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	require.NoError(t, err)
+
+	vcdData, err := ParseVCD(bytes.NewReader(content), "blinky.vcd")
+	require.NoError(t, err)
+	width, height := Dimensions(vcdData, RenderOptions{})
+	assert.Equal(t, width, img.Bounds().Dx())
+	assert.Equal(t, height, img.Bounds().Dy())
+
+	// the corner holds the dark background
+	assert.Equal(t, color.RGBA{20, 20, 20, 255}, color.RGBAModel.Convert(img.At(1, 1)))
+
+	// and the signal names are drawn in white in the left margin
+	white := false
+	for x := 10; x < 60 && !white; x++ {
+		for y := 50; y < 65; y++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r > 0xc000 && g > 0xc000 && b > 0xc000 {
+				white = true
+				break
+			}
+		}
+	}
+	assert.True(t, white, "the first label is drawn")
+}
+
+func TestRasterizeSVG_Scale(t *testing.T) {
+	svgBytes := DrawSVG(scalarSignals(2, 10))
+	width, height := Dimensions(scalarSignals(2, 10), RenderOptions{})
+
+	pngBytes, err := RasterizeSVG(svgBytes, 2)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	require.NoError(t, err)
+	assert.Equal(t, 2*width, img.Bounds().Dx())
+	assert.Equal(t, 2*height, img.Bounds().Dy())
+}
+
+func TestRasterizeSVG_Empty(t *testing.T) {
+	_, err := RasterizeSVG(nil, 1)
+	assert.EqualError(t, err, "could not rasterize an empty SVG")
+	_, err = RasterizeSVG([]byte(" \n"), 1)
+	assert.EqualError(t, err, "could not rasterize an empty SVG")
+	_, err = RasterizeSVG([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), 1)
+	assert.EqualError(t, err, "could not rasterize an SVG without a width and height")
+}
+
+func TestTranslation(t *testing.T) {
+	x, y, ok := translation("translate(160,0)")
+	assert.True(t, ok)
+	assert.Equal(t, 160.0, x)
+	assert.Equal(t, 0.0, y)
+
+	_, _, ok = translation("scale(2)")
+	assert.False(t, ok)
+}
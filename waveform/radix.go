@@ -0,0 +1,197 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Radix selects the base bus values are labelled in.
+type Radix int
+
+const (
+	// RadixAuto shows short binary values as they are and abbreviates long
+	// ones to hexadecimal.
+	RadixAuto Radix = iota
+	// RadixBin shows every bus value in binary.
+	RadixBin
+	// RadixHex shows every bus value in hexadecimal, e.g. "0x2A".
+	RadixHex
+	// RadixDec shows every bus value as an unsigned decimal number.
+	RadixDec
+	// RadixOct shows every bus value in octal, e.g. "0o52".
+	RadixOct
+	// RadixHexBin shows every bus value in hexadecimal followed by its
+	// bits, e.g. "0x2A (00101010)", where the segment is wide enough for
+	// both, and in hexadecimal alone where it is not.
+	RadixHexBin
+)
+
+// BusLabelFormat selects the text drawn in a bus box.
+type BusLabelFormat int
+
+const (
+	// FormatDefault labels bus values in the BusRadix.
+	FormatDefault BusLabelFormat = iota
+	// FormatHex labels bus values in hexadecimal, e.g. "0xAA".
+	FormatHex
+	// FormatBin labels bus values in binary, e.g. "10101010".
+	FormatBin
+	// FormatBoth labels bus values in hexadecimal followed by their bits,
+	// e.g. "0xAA (10101010)", where the segment is wide enough for both,
+	// and in hexadecimal alone where it is not.
+	FormatBoth
+)
+
+// radix returns the radix bus values are labelled in for the format.
+func (f BusLabelFormat) radix() (Radix, bool) {
+	switch f {
+	case FormatHex:
+		return RadixHex, true
+	case FormatBin:
+		return RadixBin, true
+	case FormatBoth:
+		return RadixHexBin, true
+	}
+	return RadixAuto, false
+}
+
+// valueRadix is the kind of digits a vector value is written in.
+type valueRadix int
+
+const (
+	// binaryValue is written in bits, which may be unknown "x" or "z" bits.
+	binaryValue valueRadix = iota
+	// realValue is a real number.
+	realValue
+	// otherValue is anything else, such as the name of a state.
+	otherValue
+)
+
+// normalizeVectorValue trims the whitespace around a vector value and the
+// "b" or "B" of a binary value, or the "r" or "R" of a real value, that it
+// may be written with in a VCD file. It returns the digits of the value
+// with the radix they are written in. Values without a prefix, as the
+// parser records them, are recognised by their digits.
+func normalizeVectorValue(val string) (string, valueRadix) {
+	val = strings.TrimSpace(val)
+	if len(val) > 1 {
+		switch digits := val[1:]; val[0] {
+		case 'b', 'B':
+			if isBinaryDigits(digits) {
+				return digits, binaryValue
+			}
+		case 'r', 'R':
+			if _, err := strconv.ParseFloat(digits, 64); err == nil {
+				return digits, realValue
+			}
+		}
+	}
+	switch {
+	case isBinaryDigits(val):
+		return val, binaryValue
+	case isRealValue(val):
+		return val, realValue
+	}
+	return val, otherValue
+}
+
+// isBinaryDigits reports whether a value is made up only of bits.
+func isBinaryDigits(val string) bool {
+	return val != "" && strings.Trim(val, "01xXzZ") == ""
+}
+
+// radixLabel formats a binary bus value in the given radix. Values with
+// unknown "x" or "z" bits cannot be converted and are left in binary. It
+// returns false for values that are not binary, and for RadixAuto.
+func radixLabel(val string, radix Radix) (string, bool) {
+	bits, kind := normalizeVectorValue(val)
+	if radix == RadixAuto || kind != binaryValue {
+		return "", false
+	}
+	if radix == RadixBin || strings.Trim(bits, "01") != "" {
+		return bits, true
+	}
+
+	n, _ := new(big.Int).SetString(bits, 2)
+	switch radix {
+	case RadixHex:
+		return "0x" + strings.ToUpper(n.Text(16)), true
+	case RadixOct:
+		return "0o" + n.Text(8), true
+	}
+	return n.Text(10), true
+}
+
+// valueLabel returns the text drawn for a value of the given signal, in
+// its SignalRadix, or else the BusRadix, when one is selected. The values
+// of real variables are always shown as decimal numbers.
+func (r *renderer) valueLabel(sig, val string) string {
+	if r.vcdData.isReal(sig) {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return formatReal(f, r.opts.RealPrecision)
+		}
+		return val
+	}
+	radix := r.signalRadix(sig)
+	if radix == RadixHexBin {
+		hex, ok := radixLabel(val, RadixHex)
+		if bits, _ := normalizeVectorValue(val); ok && strings.HasPrefix(hex, "0x") {
+			return padHex(hex, r.vcdData.Widths[sig]) + " (" + bits + ")"
+		}
+		radix = RadixBin
+	}
+	label, ok := radixLabel(val, radix)
+	if !ok {
+		label = busLabel(val, r.opts.RealPrecision)
+	}
+	return padHex(label, r.vcdData.Widths[sig])
+}
+
+// signalRadix returns the radix the values of a signal are labelled in.
+func (r *renderer) signalRadix(sig string) Radix {
+	if radix, ok := r.opts.SignalRadix[sig]; ok {
+		return radix
+	}
+	if radix, ok := r.opts.BusLabelFormat.radix(); ok {
+		return radix
+	}
+	return r.opts.BusRadix
+}
+
+// fitLabel returns the label of a value of the given signal to draw in a
+// segment the given number of pixels wide. The bits of a RadixHexBin or
+// FormatBoth label are left out when the segment is too narrow for them.
+func (r *renderer) fitLabel(sig, label string, width int) string {
+	if r.signalRadix(sig) == RadixHexBin && len(label)*busCharWidth > width {
+		label, _, _ = strings.Cut(label, " (")
+	}
+	return label
+}
+
+// padHex zero-pads a hexadecimal label such as "0xA" to the number of
+// digits of a bus of the given width, e.g. "0x0A" for an 8-bit bus, so that
+// the labels of a bus keep the same length. Other labels, and labels of
+// buses of unknown width, are returned unchanged.
+func padHex(label string, width int) string {
+	digits, ok := strings.CutPrefix(label, "0x")
+	if !ok || width <= 0 || digits == "" || strings.Trim(digits, "0123456789ABCDEF") != "" {
+		return label
+	}
+	return "0x" + strings.Repeat("0", max((width+3)/4-len(digits), 0)) + digits
+}
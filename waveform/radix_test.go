@@ -0,0 +1,208 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRadixLabel(t *testing.T) {
+	tests := []struct {
+		radix   Radix
+		known   string
+		unknown string
+	}{
+		{RadixBin, "00101010", "0010x010"},
+		{RadixHex, "0x2A", "0010x010"},
+		{RadixDec, "42", "0010x010"},
+		{RadixOct, "0o52", "0010x010"},
+	}
+	for _, tt := range tests {
+		label, ok := radixLabel("b00101010", tt.radix)
+		assert.True(t, ok)
+		assert.Equal(t, tt.known, label)
+
+		label, ok = radixLabel("0010x010", tt.radix)
+		assert.True(t, ok)
+		assert.Equal(t, tt.unknown, label)
+	}
+
+	// values wider than 64 bits convert too
+	label, _ := radixLabel("1"+strings.Repeat("0", 64), RadixHex)
+	assert.Equal(t, "0x10000000000000000", label)
+
+	// without a radix, and for values that are not binary, the usual
+	// label is used
+	_, ok := radixLabel("0101", RadixAuto)
+	assert.False(t, ok)
+	_, ok = radixLabel("1.5", RadixDec)
+	assert.False(t, ok)
+}
+
+func TestNormalizeVectorValue(t *testing.T) {
+	tests := []struct {
+		val    string
+		digits string
+		radix  valueRadix
+	}{
+		{"1010", "1010", binaryValue},
+		{"b1010", "1010", binaryValue},
+		{"B1010", "1010", binaryValue},
+		{" b10xz \n", "10xz", binaryValue},
+		{"\t0011", "0011", binaryValue},
+		{"x", "x", binaryValue},
+		{"r2.5", "2.5", realValue},
+		{"R-1e3", "-1e3", realValue},
+		{" 0.25 ", "0.25", realValue},
+		{"busy", "busy", otherValue},
+		{"rst", "rst", otherValue},
+		{"b", "b", otherValue},
+		{"", "", otherValue},
+	}
+	for _, tt := range tests {
+		digits, radix := normalizeVectorValue(tt.val)
+		assert.Equal(t, tt.digits, digits, "value %q", tt.val)
+		assert.Equal(t, tt.radix, radix, "value %q", tt.val)
+	}
+
+	// the labels agree however the value is written
+	for _, val := range []string{"00101010", "b00101010", "B00101010", " b00101010 "} {
+		label, ok := radixLabel(val, RadixHex)
+		assert.True(t, ok)
+		assert.Equal(t, "0x2A", label)
+		assert.Equal(t, "00101010", busLabel(val, 0))
+	}
+	assert.Equal(t, "2.500", busLabel("r2.5", 0))
+}
+
+func TestDrawSVGWithOptions_BusRadix(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus"},
+	}, map[uint64]map[string]string{
+		0: {"bus": "0101"},
+		1: {"bus": "0101"},
+		2: {"bus": "1111"},
+		3: {"bus": "1111"},
+	})
+
+	assert.Contains(t, string(DrawSVG(vcdData)), ">0101</text>")
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BusRadix: RadixDec}))
+	assert.Contains(t, svgStr, ">5</text>")
+	assert.Contains(t, svgStr, ">15</text>")
+	assert.NotContains(t, svgStr, ">0101</text>")
+}
+
+func TestDrawSVGWithOptions_SignalRadix(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"top addr", "top data", "top state"},
+	}, map[uint64]map[string]string{
+		0: {"top addr": "11111111", "top state": "0101", "top data": "0011"},
+		1: {"top addr": "11111111", "top state": "0101", "top data": "0011"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		BusRadix:    RadixOct,
+		SignalRadix: map[string]Radix{"top addr": RadixHex, "top state": RadixDec},
+	}))
+	assert.Contains(t, svgStr, ">0xFF</text>")
+	assert.Contains(t, svgStr, ">5</text>")
+	assert.Contains(t, svgStr, ">0o3</text>", "signals not listed use the global radix")
+}
+
+func TestDrawSVGWithOptions_HexBinRadix(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus"},
+		Widths:  map[string]int{"bus": 8},
+	}, map[uint64]map[string]string{
+		0: {"bus": "10101010"},
+		1: {"bus": "10101010"},
+		2: {"bus": "10101010"},
+		3: {"bus": "10101010"},
+		4: {"bus": "10101010"},
+		5: {"bus": "00001111"},
+		6: {"bus": "00001111"},
+		7: {"bus": "0000x111"},
+		8: {"bus": "0000x111"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BusRadix: RadixHexBin}))
+
+	// the long segment has room for the bits, the short one does not
+	assert.Contains(t, svgStr, ">0xAA (10101010)</text>")
+	assert.Contains(t, svgStr, ">0x0F</text>")
+	assert.NotContains(t, svgStr, "(00001111)</text>")
+
+	// values with unknown bits have no hexadecimal label
+	assert.Contains(t, svgStr, ">0000x111</text>")
+}
+
+func TestDrawSVGWithOptions_BusLabelFormat(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus"},
+		Widths:  map[string]int{"bus": 8},
+	}, map[uint64]map[string]string{
+		0: {"bus": "10101010"},
+		5: {"bus": "00001111"},
+		6: {"bus": "00001111"},
+	})
+	vcdData.Times = []uint64{0, 1, 2, 3, 4, 5, 6}
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BusLabelFormat: FormatBoth}))
+	assert.Contains(t, svgStr, ">0xAA (10101010)</text>")
+	assert.Contains(t, svgStr, ">0x0F</text>", "a narrow segment falls back to hexadecimal")
+
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{BusLabelFormat: FormatBin}))
+	assert.Contains(t, svgStr, ">10101010</text>")
+
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{BusLabelFormat: FormatHex, BusRadix: RadixDec}))
+	assert.Contains(t, svgStr, ">0xAA</text>", "the format takes the place of BusRadix")
+
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{
+		BusLabelFormat: FormatHex,
+		SignalRadix:    map[string]Radix{"bus": RadixDec},
+	}))
+	assert.Contains(t, svgStr, ">170</text>", "a SignalRadix overrides the format")
+}
+
+func TestPadHex(t *testing.T) {
+	assert.Equal(t, "0x0A", padHex("0xA", 8))
+	assert.Equal(t, "0x00A", padHex("0xA", 9))
+	assert.Equal(t, "0xFF", padHex("0xFF", 8))
+	assert.Equal(t, "0xA", padHex("0xA", 0), "without a width the label is unchanged")
+	assert.Equal(t, "0xA?", padHex("0xA?", 16))
+	assert.Equal(t, "1010", padHex("1010", 8))
+}
+
+func TestDrawSVGWithOptions_HexPaddedToWidth(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"data"},
+		Widths:  map[string]int{"data": 8},
+	}, map[uint64]map[string]string{
+		0: {"data": "00001010"},
+		1: {"data": "00001010"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BusRadix: RadixHex}))
+	assert.Contains(t, svgStr, ">0x0A</text>")
+	assert.NotContains(t, svgStr, ">0xA</text>")
+
+	// without a declared width the label is not padded
+	vcdData.Widths = nil
+	assert.Contains(t, string(DrawSVGWithOptions(vcdData, RenderOptions{BusRadix: RadixHex})), ">0xA</text>")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultPowerGroundPatterns are the names of power and ground signals
+// recognised when PowerGroundDetection does not list its own.
+var DefaultPowerGroundPatterns = []string{"vdd*", "vcc*", "vss*", "vee*", "gnd*"}
+
+// PowerGroundDetection configures which signals are drawn as power or
+// ground rails. A signal is a rail when it holds the same level, 0 or 1,
+// for the whole trace and its name matches one of the patterns.
+type PowerGroundDetection struct {
+	// Patterns are shell style patterns, as accepted by path.Match, that
+	// are matched case-insensitively against the signal's name without
+	// its scope. An empty list uses DefaultPowerGroundPatterns.
+	Patterns []string
+
+	// AnyConstant treats every signal held at a constant level as a rail,
+	// whatever its name.
+	AnyConstant bool
+}
+
+// matches reports whether a signal name follows the power and ground
+// naming convention.
+func (p *PowerGroundDetection) matches(name string) bool {
+	patterns := p.Patterns
+	if len(patterns) == 0 {
+		patterns = DefaultPowerGroundPatterns
+	}
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// railLevel returns the level a signal is tied to when it is detected as a
+// power or ground rail.
+func (r *renderer) railLevel(sig string) (string, bool) {
+	detection := r.opts.PowerGroundDetection
+	if detection == nil {
+		return "", false
+	}
+
+	level := r.value(sig, r.times[0])
+	if level != "0" && level != "1" {
+		return "", false
+	}
+	for _, t := range r.times[1:] {
+		if r.value(sig, t) != level {
+			return "", false
+		}
+	}
+	if !detection.AnyConstant && !detection.matches(r.vcdData.DisplayName(sig, ScopeLeafOnly, 0)) {
+		return "", false
+	}
+	return level, true
+}
+
+// drawRail draws a power or ground signal as a thin line through the
+// middle of its row, labelled with the supply it is tied to.
+func (r *renderer) drawRail(level string, y int) {
+	x0 := r.timeX(r.times[0])
+	x1 := r.timeX(r.times[len(r.times)-1])
+	mid := y + r.signalHeight/2
+	r.canvas.Line(x0, mid, x1, mid, r.theme.Rail)
+	if r.thumbnail {
+		return
+	}
+
+	label := "GND"
+	if level == "1" {
+		label = "VDD"
+	}
+	r.canvas.Text(x0+4, mid-4, label, r.theme.RailText)
+}
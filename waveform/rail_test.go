@@ -0,0 +1,60 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGWithOptions_PowerGroundDetection(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"top vdd", "top vss", "clk", "en"},
+		Scopes:  map[string][]string{"top vdd": {"top"}, "top vss": {"top"}},
+	}, map[uint64]map[string]string{
+		0: {"top vdd": "1", "top vss": "0", "clk": "0", "en": "1"},
+		1: {"top vdd": "1", "top vss": "0", "clk": "1", "en": "1"},
+		2: {"top vdd": "1", "top vss": "0", "clk": "0", "en": "1"},
+	})
+	group := func(svgStr, id string) string {
+		g := svgStr[strings.Index(svgStr, `<g id="`+id+`">`):]
+		return g[:strings.Index(g, "</g>")]
+	}
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{PowerGroundDetection: &PowerGroundDetection{}}))
+	vdd := group(svgStr, "signal-top-vdd")
+	assert.Contains(t, vdd, `<line x1="150" y1="60" x2="190" y2="60" style="`+railStyle+`" />`)
+	assert.Contains(t, vdd, `<text x="154" y="56" style="`+railTextStyle+`" >VDD</text>`)
+	assert.Equal(t, 1, strings.Count(vdd, "<line"))
+	assert.Contains(t, group(svgStr, "signal-top-vss"), ">GND</text>")
+
+	// a constant signal that is not named as a supply keeps its waveform
+	en := group(svgStr, "signal-en")
+	assert.NotContains(t, en, railStyle)
+	assert.Contains(t, en, wireStyle)
+	assert.NotContains(t, group(svgStr, "signal-clk"), railStyle)
+
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{PowerGroundDetection: &PowerGroundDetection{AnyConstant: true}}))
+	assert.Contains(t, group(svgStr, "signal-en"), ">VDD</text>")
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{PowerGroundDetection: &PowerGroundDetection{Patterns: []string{"EN"}}}))
+	assert.Contains(t, group(svgStr, "signal-en"), ">VDD</text>")
+	assert.NotContains(t, group(svgStr, "signal-top-vdd"), railStyle)
+
+	plain := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.NotContains(t, plain, railStyle)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+// rasterSize returns the width and height of a bit raster of the given
+// number of signals, one pixel per time unit and per signal.
+func rasterSize(times []uint64, signals int) (int, int) {
+	width := 1
+	if len(times) > 0 {
+		width = int(times[len(times)-1]) + 1
+	}
+	return width, max(signals, 1)
+}
+
+// rasterStyle returns the style of the raster pixels of a signal holding
+// the given value.
+func (r *renderer) rasterStyle(val string) string {
+	switch val {
+	case "1":
+		return r.theme.RasterHigh
+	case "0":
+		return r.theme.RasterLow
+	}
+	return r.theme.RasterUnknown
+}
+
+// drawBitRaster draws the whole diagram as a bit raster, with one row of
+// pixels per signal. Each run of a level is drawn as a single rectangle
+// lasting until the next change, and times before a signal's first value
+// are left as background.
+func (r *renderer) drawBitRaster() {
+	r.canvas.Start(r.width, r.height, `shape-rendering="crispEdges"`)
+	r.drawBackground()
+	for i, sig := range r.signals {
+		start, val := 0, ""
+		for _, c := range r.changes[sig] {
+			if c.Value == "" || c.Value == val {
+				continue
+			}
+			if val != "" {
+				r.canvas.Rect(start, i, int(c.Time)-start, 1, r.rasterStyle(val))
+			}
+			start, val = int(c.Time), c.Value
+		}
+		if val != "" {
+			r.canvas.Rect(start, i, r.width-start, 1, r.rasterStyle(val))
+		}
+	}
+	r.canvas.End()
+}
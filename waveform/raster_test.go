@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGWithOptions_BitRaster(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{}
+	for i := range 200 {
+		vcdData.Signals = append(vcdData.Signals, fmt.Sprintf("mem[%d]", i))
+	}
+	for t := range 10 {
+		step := map[string]string{}
+		for i, sig := range vcdData.Signals {
+			// even signals are high for the first half of the trace, odd
+			// signals stay low
+			step[sig] = "0"
+			if i%2 == 0 && t < 5 {
+				step[sig] = "1"
+			}
+		}
+		sim[uint64(t)] = step
+	}
+	sim[3]["mem[1]"] = "x"
+	vcdData.SetSnapshots(sim)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BitRaster: true}))
+	assert.Contains(t, svgStr, `<svg width="10" height="200"`)
+	assert.Contains(t, svgStr, `shape-rendering="crispEdges"`)
+
+	// each run of a level is one rectangle in the row of its signal
+	assert.Contains(t, svgStr, `<rect x="0" y="0" width="5" height="1" style="`+rasterHighStyle+`" />`)
+	assert.Contains(t, svgStr, `<rect x="5" y="0" width="5" height="1" style="`+rasterLowStyle+`" />`)
+	assert.Contains(t, svgStr, `<rect x="0" y="1" width="3" height="1" style="`+rasterLowStyle+`" />`)
+	assert.Contains(t, svgStr, `<rect x="3" y="1" width="1" height="1" style="`+rasterUnknownStyle+`" />`)
+	assert.Contains(t, svgStr, `<rect x="4" y="1" width="6" height="1" style="`+rasterLowStyle+`" />`)
+	assert.Equal(t, 100, strings.Count(svgStr, rasterHighStyle))
+	assert.NotContains(t, svgStr, "<text")
+
+	w, h := Dimensions(vcdData, RenderOptions{BitRaster: true})
+	assert.Equal(t, 10, w)
+	assert.Equal(t, 200, h)
+}
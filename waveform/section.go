@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"regexp"
+	"slices"
+)
+
+// scopeIndent is the width in pixels each level of the scope tree indents
+// its headers and signal labels by.
+const scopeIndent = 10
+
+// GroupSpec names a section of the diagram and the pattern selecting the
+// signals drawn within it, e.g. "AXI Write" for `^(aw|w|b).*`.
+type GroupSpec struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// section is a header drawn in its own row above the signals of a group.
+// Sections of the scope tree are indented by their depth within it.
+type section struct {
+	name  string
+	slot  int
+	depth int
+}
+
+// groupSections orders the signals by the group they first match, keeping
+// their original order within each group, and assigns every signal and
+// section header a row. Signals matching no group come first. Groups that
+// match no signals are left out.
+func groupSections(signals []string, groups []GroupSpec) ([]string, []int, []section) {
+	members := make([][]string, len(groups))
+	var ungrouped []string
+	for _, sig := range signals {
+		matched := false
+		for g, group := range groups {
+			if group.Pattern != nil && group.Pattern.MatchString(sig) {
+				members[g] = append(members[g], sig)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			ungrouped = append(ungrouped, sig)
+		}
+	}
+
+	ordered := make([]string, 0, len(signals))
+	slots := make([]int, 0, len(signals))
+	var sections []section
+	for _, sig := range ungrouped {
+		ordered = append(ordered, sig)
+		slots = append(slots, len(slots))
+	}
+	slot := len(slots)
+	for g, group := range groups {
+		if len(members[g]) == 0 {
+			continue
+		}
+		sections = append(sections, section{name: group.Name, slot: slot})
+		slot++
+		for _, sig := range members[g] {
+			ordered = append(ordered, sig)
+			slots = append(slots, slot)
+			slot++
+		}
+	}
+	return ordered, slots, sections
+}
+
+// scopeSections orders the signals into the tree of scopes they were
+// declared in, with a section header for each scope above its signals. A
+// scope's own signals come before those of the scopes nested within it,
+// and signals keep their original order within a scope. It also returns
+// the depth of each signal within the tree.
+func scopeSections(signals []string, scopes map[string][]string) ([]string, []int, []section, map[string]int) {
+	ordered := slices.Clone(signals)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		return slices.Compare(scopes[a], scopes[b])
+	})
+
+	slots := make([]int, 0, len(ordered))
+	depths := make(map[string]int, len(ordered))
+	var sections []section
+	var open []string
+	slot := 0
+	for _, sig := range ordered {
+		path := scopes[sig]
+		common := 0
+		for common < min(len(path), len(open)) && path[common] == open[common] {
+			common++
+		}
+		for depth := common; depth < len(path); depth++ {
+			sections = append(sections, section{name: path[depth], slot: slot, depth: depth})
+			slot++
+		}
+		open = path
+		depths[sig] = len(path)
+		slots = append(slots, slot)
+		slot++
+	}
+	return ordered, slots, sections, depths
+}
+
+// drawSections draws the header of each section, with a separator line
+// across the diagram above each section at the top of the scope tree.
+func (r *renderer) drawSections() {
+	if len(r.sections) == 0 {
+		return
+	}
+
+	r.canvas.Gid("sections")
+	for _, s := range r.sections {
+		y := r.slotY(s.slot)
+		if s.depth == 0 {
+			r.canvas.Line(0, y-r.signalGap/2, r.width-r.sidebar, y-r.signalGap/2, r.theme.Separator)
+		}
+		if !r.thumbnail {
+			r.canvas.Text(10+s.depth*scopeIndent, y+r.signalHeight*3/4, s.name, r.theme.Section)
+		}
+	}
+	r.canvas.Gend()
+}
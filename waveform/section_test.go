@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGWithOptions_SignalGroups(t *testing.T) {
+	signals := []string{"awaddr", "awvalid", "bready", "clk", "rdata", "wdata"}
+	vcdData := &VcdData{Signals: signals}
+	sim := map[uint64]map[string]string{0: {}, 1: {}}
+	for _, sig := range signals {
+		sim[0][sig] = "0"
+		sim[1][sig] = "1"
+	}
+	vcdData.SetSnapshots(sim)
+	opts := RenderOptions{SignalGroups: []GroupSpec{
+		{Name: "AXI Write", Pattern: regexp.MustCompile(`^(aw|w|b)`)},
+		{Name: "AXI Read", Pattern: regexp.MustCompile(`^(ar|r)`)},
+		{Name: "Unused", Pattern: regexp.MustCompile(`^irq`)},
+	}}
+
+	svgStr := string(DrawSVGWithOptions(vcdData, opts))
+	label := func(text string, slot int) string {
+		return fmt.Sprintf(`<text x="10" y="%d" style="%s" >%s</text>`, 50+slot*30+10, textStyle, text)
+	}
+	header := func(text string, slot int) string {
+		return fmt.Sprintf(`<text x="10" y="%d" style="%s" >%s</text>`, 50+slot*30+15, sectionStyle, text)
+	}
+
+	// ungrouped signals come first, then each group under its header
+	assert.Contains(t, svgStr, label("clk", 0))
+	assert.Contains(t, svgStr, header("AXI Write", 1))
+	assert.Contains(t, svgStr, label("awaddr", 2))
+	assert.Contains(t, svgStr, label("awvalid", 3))
+	assert.Contains(t, svgStr, label("bready", 4))
+	assert.Contains(t, svgStr, label("wdata", 5))
+	assert.Contains(t, svgStr, header("AXI Read", 6))
+	assert.Contains(t, svgStr, label("rdata", 7))
+	assert.NotContains(t, svgStr, ">Unused</text>")
+	assert.Contains(t, svgStr, `<line x1="0" y1="75" x2="200" y2="75" style="`+separatorStyle+`" />`)
+
+	// the headers take up rows of their own
+	_, height := Dimensions(vcdData, opts)
+	assert.Equal(t, 8*30+100, height)
+}
+
+func TestDrawSVGWithOptions_ScopeTree(t *testing.T) {
+	scopes := map[string][]string{
+		"tb done":            {"tb"},
+		"top clk":            {"top"},
+		"top cpu alu result": {"top", "cpu", "alu"},
+		"top cpu pc":         {"top", "cpu"},
+		"top rst":            {"top"},
+	}
+	vcdData := &VcdData{
+		Signals: []string{"tb done", "top clk", "top cpu alu result", "top cpu pc", "top rst"},
+		Scopes:  scopes,
+	}
+	sim := map[uint64]map[string]string{0: {}, 1: {}}
+	for sig := range scopes {
+		sim[0][sig] = "0"
+		sim[1][sig] = "1"
+	}
+	vcdData.SetSnapshots(sim)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{ScopeTree: true}))
+	row := func(text, style string, slot, depth, offset int) string {
+		return fmt.Sprintf(`<text x="%d" y="%d" style="%s" >%s</text>`, 10+depth*scopeIndent, 50+slot*30+offset, style, text)
+	}
+	label := func(text string, slot, depth int) string { return row(text, textStyle, slot, depth, 10) }
+	header := func(text string, slot, depth int) string { return row(text, sectionStyle, slot, depth, 15) }
+
+	// each scope is a header above its own signals, then its nested scopes
+	assert.Contains(t, svgStr, header("tb", 0, 0))
+	assert.Contains(t, svgStr, label("done", 1, 1))
+	assert.Contains(t, svgStr, header("top", 2, 0))
+	assert.Contains(t, svgStr, label("clk", 3, 1))
+	assert.Contains(t, svgStr, label("rst", 4, 1))
+	assert.Contains(t, svgStr, header("cpu", 5, 1))
+	assert.Contains(t, svgStr, label("pc", 6, 2))
+	assert.Contains(t, svgStr, header("alu", 7, 2))
+	assert.Contains(t, svgStr, label("result", 8, 3))
+	assert.Equal(t, 2, strings.Count(svgStr, separatorStyle), "only top level scopes are separated")
+
+	_, height := Dimensions(vcdData, RenderOptions{ScopeTree: true})
+	assert.Equal(t, 9*30+100, height)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import "strings"
+
+// sidebarWidth is the width reserved beside the diagram for the sidebar.
+const sidebarWidth = 160
+
+// Sidebar configures the panel listing the value of every signal at a
+// cursor time.
+type Sidebar struct {
+	// Time is the cursor time whose values are listed.
+	Time uint64
+	// Left places the panel to the left of the diagram rather than the
+	// right.
+	Left bool
+}
+
+// drawSidebar draws the sidebar panel when one is requested, with a swatch
+// in the colour of each signal beside its value at the cursor time, level
+// with the signal's row.
+func (r *renderer) drawSidebar() {
+	if r.opts.Sidebar == nil {
+		return
+	}
+
+	x := r.width - r.sidebar
+	if r.opts.Sidebar.Left {
+		x = 0
+	}
+	snapshot := r.vcdData.SnapshotAt(r.opts.Sidebar.Time)
+
+	r.canvas.Gid("sidebar")
+	r.canvas.Rect(x, 0, r.sidebar, r.height, r.theme.Sidebar)
+	r.canvas.Text(x+10, 30, "@ "+r.timeLabel(r.opts.Sidebar.Time), r.theme.BusValue)
+	for i, sig := range r.signals {
+		y := r.rowY(i)
+		val, ok := snapshot[sig]
+		label := "-"
+		if ok {
+			label = val
+			if r.isBus(sig, val) {
+				label = r.valueLabel(sig, val)
+			}
+		}
+		r.canvas.Rect(x+10, y+r.signalHeight/4, r.signalHeight/2, r.signalHeight/2, "fill:"+r.swatchColor(sig, val))
+		r.canvas.Text(x+10+r.signalHeight, y+r.signalHeight*3/4, label, r.theme.BusValue)
+	}
+	r.canvas.Gend()
+}
+
+// swatchColor returns the colour a signal holding the given value is drawn
+// in, taken from the stroke of its wire or bus style.
+func (r *renderer) swatchColor(sig, val string) string {
+	if isUnknownBit(val) && !r.isBus(sig, val) {
+		return styleColor(r.theme.Unknown, "fill")
+	}
+	if !r.isBus(sig, val) {
+		return styleColor(r.levelStyle(val), "stroke")
+	}
+	if color, ok := r.opts.ValueColors[sig][val]; ok {
+		return color
+	}
+	return styleColor(r.theme.Bus, "stroke")
+}
+
+// styleColor returns the value of a colour property within an SVG style
+// string, e.g. "green" for the stroke of "stroke:green;stroke-width:1".
+func styleColor(style, property string) string {
+	for _, decl := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if ok && strings.TrimSpace(name) == property {
+			return strings.TrimSpace(value)
+		}
+	}
+	return "grey"
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGWithOptions_Sidebar(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "data", "late"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "data": "0000"},
+		1: {"clk": "1", "data": "1010"},
+		2: {"clk": "0", "data": "1010"},
+		3: {"clk": "1", "data": "0110"},
+	})
+
+	plain := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{Sidebar: &Sidebar{Time: 2}}))
+	assert.NotContains(t, plain, `<g id="sidebar">`)
+	assert.Contains(t, svgStr, `<svg width="400" height="190"`)
+
+	panel := svgStr[strings.Index(svgStr, `<g id="sidebar">`):]
+	assert.Contains(t, panel, `<rect x="240" y="0" width="160" height="190"`)
+	assert.Contains(t, panel, ">@ 2</text>")
+	assert.Contains(t, panel, `<rect x="250" y="55" width="10" height="10" style="fill:green" />`)
+	assert.Contains(t, panel, `<text x="270" y="65" style="`+busValueStyle+`" >0</text>`)
+	assert.Contains(t, panel, `style="fill:cyan" />`)
+	assert.Contains(t, panel, ">1010</text>")
+	assert.Contains(t, panel, ">-</text>")
+
+	// on the left the diagram is moved across to make room for the panel
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{Sidebar: &Sidebar{Time: 3, Left: true}}))
+	assert.Contains(t, svgStr, `<g transform="translate(160,0)">`)
+	assert.Contains(t, svgStr, `<rect x="0" y="0" width="160" height="190"`)
+	assert.Contains(t, svgStr, ">0110</text>")
+
+	w, h := Dimensions(vcdData, RenderOptions{Sidebar: &Sidebar{}})
+	assert.Equal(t, 400, w)
+	assert.Equal(t, 190, h)
+}
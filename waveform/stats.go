@@ -0,0 +1,157 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Stats summarises what was parsed from a trace, to help tell whether an
+// unexpected diagram comes from the input or from the rendering.
+type Stats struct {
+	Signals      int
+	TimeSteps    int
+	ValueChanges int
+	MinTime      uint64
+	MaxTime      uint64
+	Timescale    Timescale
+}
+
+// Stats counts the signals, time steps and value changes of the trace. The
+// first value of each signal counts as a change.
+func (v *VcdData) Stats() Stats {
+	stats := Stats{
+		Signals:   len(v.Signals),
+		TimeSteps: len(v.Times),
+		Timescale: v.Timescale,
+	}
+	if len(v.Times) > 0 {
+		stats.MinTime, stats.MaxTime = v.Times[0], v.Times[len(v.Times)-1]
+	}
+	for _, changes := range v.Changes {
+		stats.ValueChanges += len(changes)
+	}
+	return stats
+}
+
+// String returns the statistics on a single line.
+func (s Stats) String() string {
+	timescale := "none"
+	if s.Timescale != (Timescale{}) {
+		timescale = s.Timescale.String()
+	}
+	return fmt.Sprintf("signals: %d, time steps: %d, value changes: %d, times: %d to %d, timescale: %s",
+		s.Signals, s.TimeSteps, s.ValueChanges, s.MinTime, s.MaxTime, timescale)
+}
+
+// ScanStats summarises a VCD file read from r like Stats, in a single pass
+// over its value changes that keeps only the declarations and the latest
+// values of each signal, so the simulation is never held in memory. The
+// name identifies the file in errors. Unlike Stats, Signals counts every
+// declared signal, including those that never change.
+func ScanStats(r io.Reader, name string) (Stats, error) {
+	lines := bufio.NewReader(r)
+	vcdData, rest, err := readDeclarations(lines, name)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{
+		Signals:   len(vcdData.Signals),
+		TimeSteps: 1,
+		Timescale: vcdData.Timescale,
+	}
+
+	// the last two changes of each signal are enough for appendChange to
+	// replace a change made at the same time
+	recent := map[string][]Change{}
+	var s uint64
+	setValue := func(name, value string) {
+		changes := recent[name]
+		n := len(changes)
+		changes = appendChange(changes, s, value)
+		stats.ValueChanges += len(changes) - n
+		recent[name] = changes[max(0, len(changes)-2):]
+	}
+	applyValueChange := func(code, value string) {
+		for _, name := range vcdData.Decl[code] {
+			setValue(name, value)
+		}
+	}
+
+	words := bufio.NewScanner(io.MultiReader(strings.NewReader(rest), lines))
+	words.Split(bufio.ScanWords)
+	next := func() string {
+		if !words.Scan() {
+			return ""
+		}
+		return words.Text()
+	}
+	var held map[string]string
+	for word := next(); word != ""; word = next() {
+		switch {
+		case word == "$comment":
+			for word != "" && word != "$end" {
+				word = next()
+			}
+		case word == "$dumpoff":
+			// mirror ProcessVcd: every variable is unknown until $dumpon
+			held = map[string]string{}
+			for name, changes := range recent {
+				held[name] = changes[len(changes)-1].Value
+			}
+			for _, names := range vcdData.Decl {
+				for _, name := range names {
+					setValue(name, "x")
+				}
+			}
+		case word == "$dumpon":
+			for name, value := range held {
+				setValue(name, value)
+			}
+			held = nil
+		case word[0] == '$':
+			// $dumpvars, $dumpall and $end only group value changes
+		case word[0] == '#':
+			t, err := strconv.ParseUint(word[1:], 10, 64)
+			if err != nil {
+				return Stats{}, fmt.Errorf("parse error: %s: invalid time %q", name, word)
+			}
+			s = t
+			if s > stats.MaxTime {
+				stats.MaxTime = s
+				stats.TimeSteps++
+			}
+		case strings.ContainsRune("bBrR", rune(word[0])):
+			code := next()
+			if code == "" {
+				return Stats{}, fmt.Errorf("parse error: %s: %q has no identifier code", name, word)
+			}
+			applyValueChange(code, word[1:])
+		case strings.ContainsRune("01xXzZ", rune(word[0])) && len(word) > 1:
+			applyValueChange(word[1:], word[:1])
+		default:
+			return Stats{}, fmt.Errorf("parse error: %s: unexpected %q", name, word)
+		}
+	}
+	if err := words.Err(); err != nil {
+		return Stats{}, fmt.Errorf("could not read file: %w", err)
+	}
+	return stats, nil
+}
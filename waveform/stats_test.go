@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVcdData_Stats(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(simpleVcd)), "simple.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats := vcdData.Stats()
+	assert.Equal(t, Stats{
+		Signals:      2,
+		TimeSteps:    3,
+		ValueChanges: 6,
+		MinTime:      0,
+		MaxTime:      2,
+		Timescale:    Timescale{Magnitude: 1, Unit: "ns"},
+	}, stats)
+	assert.Equal(t, "signals: 2, time steps: 3, value changes: 6, times: 0 to 2, timescale: 1ns", stats.String())
+
+	empty := (&VcdData{}).Stats()
+	assert.Equal(t, Stats{}, empty)
+	assert.Contains(t, empty.String(), "timescale: none")
+}
+
+func TestScanStats(t *testing.T) {
+	blinky, err := os.ReadFile("../example/blinky.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, content := range map[string]string{
+		"simple.vcd":  simpleVcd,
+		"comment.vcd": commentVcd,
+		"sparse.vcd":  sparseVcd,
+		"dumpall.vcd": dumpallVcd,
+		"dumpoff.vcd": dumpoffVcd,
+		"alias.vcd":   aliasVcd,
+		"real.vcd":    realVcd,
+		"slice.vcd":   sliceVcd,
+		"blinky.vcd":  string(blinky),
+	} {
+		t.Run(name, func(t *testing.T) {
+			vcdData, err := ParseVCD(bytes.NewReader([]byte(content)), name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			header, err := ParseDeclarationsOnly(strings.NewReader(content), name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := vcdData.Stats()
+			want.Signals = len(header.Signals)
+			stats, err := ScanStats(strings.NewReader(content), name)
+			assert.NoError(t, err)
+			assert.Equal(t, want, stats)
+		})
+	}
+
+	_, err = ScanStats(strings.NewReader(simpleVcd+"#4 garbage\n"), "bad.vcd")
+	assert.EqualError(t, err, `parse error: bad.vcd: unexpected "garbage"`)
+}
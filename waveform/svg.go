@@ -19,9 +19,13 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
-	"sort"
+	"io"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	svg "github.com/ajstarks/svgo"
 )
@@ -31,32 +35,568 @@ const (
 	signalGap    = 10
 	stepWidth    = 20
 	leftMargin   = 150
+	rightMargin  = 10
+	busCharWidth = 6 // approximate advance of a 10px monospace glyph
+
+	// minLabelSpacing is the closest that the centres of time labels are
+	// drawn together. Wider labels are spaced further apart.
+	minLabelSpacing = 40
+
+	// activityHeight is the height of the tallest bar of the activity ruler
+	// and activityBinWidth the width in pixels of the stretch of time each
+	// bar counts the transitions of
+	activityHeight   = 15
+	activityBinWidth = 10
+
+	// firstCrossWidth is the widest that the crossing of a bus changing at
+	// the second time is drawn, at the end of its level first interval
+	firstCrossWidth = 4
 )
 
+// defaultRealPrecision is the number of decimal places shown for real values.
+const defaultRealPrecision = 3
+
 const (
-	backgroundStyle = "fill:rgba(20,20,20,1)"
-	wireStyle       = "stroke:green;stroke-width:1;"
-	shadowStyle     = "stroke:rgba(0,0,0,0.5);stroke-width:1;"
-	busStyle        = "stroke:cyan;stroke-width:1"
-	busFillStyle    = "fill:cyan;fill-opacity:0.1"
-	busValueStyle   = "font-size:10px; font-family:monospace; text-anchor:start; fill:white; text-shadow:1px 1px 1px black;"
-	textStyle       = "font-family:monospace; font-size:12px; fill:white; text-shadow:1px 1px 1px black;"
-	tickTextStyle   = "font-size:10px; font-family:monospace; text-anchor:middle; fill:white; text-shadow:1px 1px 1px black;"
-	tickStyle       = "stroke:grey;stroke-width:1"
-	gridStyle       = "stroke:#303030;stroke-width:1;stroke-dasharray:1,1"
-	axisStyle       = "stroke:#606060;stroke-width:2"
+	backgroundStyle      = "fill:rgba(20,20,20,1)"
+	wireStyle            = "stroke:green;stroke-width:1;"
+	tristateStyle        = "stroke:yellow;stroke-width:1;"
+	shadowStyle          = "stroke:rgba(0,0,0,0.5);stroke-width:1;"
+	busStyle             = "stroke:cyan;stroke-width:1"
+	busFillStyle         = "fill:cyan;fill-opacity:0.1"
+	busColorStyle        = "fill:%s;fill-opacity:0.4"
+	shadowColorStyle     = "stroke:%s;stroke-opacity:%g;stroke-width:1;"
+	wireColorStyle       = "stroke:%s;stroke-width:1;"
+	busColorLineStyle    = "stroke:%s;stroke-width:1"
+	gatedStyle           = "fill:orange;fill-opacity:0.15"
+	edgeMarkerStyle      = "fill:white;fill-opacity:0.6"
+	analogStyle          = "fill:none;stroke:cyan;stroke-width:1"
+	sampleHighStyle      = "fill:lime;stroke:black;stroke-width:0.5"
+	sampleLowStyle       = "fill:orange;stroke:black;stroke-width:0.5"
+	sampleBusStyle       = "fill:white;stroke:black;stroke-width:0.5"
+	highlightStyle       = "fill:yellow;fill-opacity:0.12"
+	dontCareStyle        = "fill:grey;fill-opacity:0.35;stroke:grey;stroke-width:1;stroke-dasharray:2,2"
+	subtitleStyle        = "font-family:monospace; font-size:8px; fill:#a0a0a0;"
+	durationStyle        = "font-family:monospace; font-size:7px; text-anchor:middle; fill:#808080;"
+	activityStyle        = "fill:cyan;fill-opacity:0.5"
+	directionStyle       = "font-family:monospace; font-size:10px; text-anchor:middle; fill:#c0c0c0;"
+	transactionStyle     = "fill:magenta;fill-opacity:0.12;stroke:magenta;stroke-width:1"
+	transactionTextStyle = "font-family:monospace; font-size:8px; fill:magenta;"
+	cursorStyle          = "stroke:orange;stroke-width:1"
+	cursorTextStyle      = "font-family:monospace; font-size:8px; fill:orange;"
+	commentStyle         = "font-family:monospace; font-size:8px; fill:#c0c0c0;"
+	tooltipStyle         = "fill:black;fill-opacity:0"
+	sidebarStyle         = "fill:rgba(30,30,30,1);stroke:#404040;stroke-width:1"
+	sectionStyle         = "font-family:monospace; font-size:12px; font-weight:bold; fill:#e0e0e0;"
+	unknownStyle         = "fill:red;fill-opacity:0.25;stroke:red;stroke-width:1"
+	undefinedStyle       = "fill:grey;fill-opacity:0.1;stroke:grey;stroke-width:1;stroke-dasharray:4,2"
+	separatorStyle       = "stroke:#505050;stroke-width:1"
+	railStyle            = "stroke:#c08040;stroke-width:3"
+	railTextStyle        = "font-family:monospace; font-size:8px; fill:#c08040;"
+	rasterHighStyle      = "fill:#00ff00"
+	rasterLowStyle       = "fill:#003000"
+	rasterUnknownStyle   = "fill:#808080"
+	busValueStyle        = "font-size:10px; font-family:monospace; text-anchor:start; fill:white; text-shadow:1px 1px 1px black;"
+	textStyle            = "font-family:monospace; font-size:12px; fill:white; text-shadow:1px 1px 1px black;"
+	tickTextStyle        = "font-size:10px; font-family:monospace; text-anchor:middle; fill:white; text-shadow:1px 1px 1px black;"
+	tickStyle            = "stroke:grey;stroke-width:1"
+	gridStyle            = "stroke:#303030;stroke-width:1;stroke-dasharray:1,1"
+	axisStyle            = "stroke:#606060;stroke-width:2"
 )
 
-// drawLineWithShadow draws a line from (x0,y0) to (x1,y1) with a shadow effect.
-// It first draws a shadow line with a slight offset and then draws the main line
-// using the specified style.
-func drawLineWithShadow(canvas *svg.SVG, x0 int, y0 int, x1 int, y1 int, style string) {
-	if y0 == y1 {
-		canvas.Line(x0, y0+1, x1, y1+1, shadowStyle)
-	} else {
-		canvas.Line(x0+1, y0, x1+1, y1, shadowStyle)
+// isRealValue reports whether a value holds a real number, as opposed to a
+// binary or state string.
+func isRealValue(val string) bool {
+	if !strings.ContainsAny(val, ".eE") {
+		return false
+	}
+	_, err := strconv.ParseFloat(val, 64)
+	return err == nil
+}
+
+// formatReal formats a real value with the given number of decimal places.
+// Zero uses the default precision, and a negative precision uses the fewest
+// digits that represent the value exactly.
+func formatReal(f float64, precision int) string {
+	if precision == 0 {
+		precision = defaultRealPrecision
+	}
+	return strconv.FormatFloat(f, 'f', max(precision, -1), 64)
+}
+
+// busLabel returns the text drawn inside a bus segment for the given value.
+// Real values are formatted to the requested precision and long binary
+// values are abbreviated to hexadecimal. Values mixing known and unknown
+// bits are shown in partial hexadecimal.
+func busLabel(val string, realPrecision int) string {
+	digits, kind := normalizeVectorValue(val)
+	switch kind {
+	case realValue:
+		f, _ := strconv.ParseFloat(digits, 64)
+		return formatReal(f, realPrecision)
+	case otherValue:
+		return digits
+	}
+	if partial, ok := partialHex(digits); ok {
+		return partial
+	}
+
+	if len(digits) > 8 {
+		if i, err := strconv.ParseUint(digits, 2, 64); err == nil {
+			return fmt.Sprintf("0x%X", i)
+		}
+	}
+	return digits
+}
+
+// partialHex formats a binary value that mixes known bits with unknown
+// "x" or "z" bits as hexadecimal, showing each nibble holding an unknown
+// bit as "?", e.g. "1010xxxx" becomes "0xA?". It returns false for values
+// that are not binary or whose bits are all known or all unknown.
+func partialHex(bits string) (string, bool) {
+	known := strings.Count(bits, "0") + strings.Count(bits, "1")
+	if known == 0 || known == len(bits) || strings.Trim(bits, "01xXzZ") != "" {
+		return "", false
+	}
+
+	// extend to whole nibbles, repeating a leading unknown bit as in a VCD
+	pad := "0"
+	if strings.ContainsAny(bits[:1], "xXzZ") {
+		pad = bits[:1]
+	}
+	bits = strings.Repeat(pad, (4-len(bits)%4)%4) + bits
+
+	var label strings.Builder
+	label.WriteString("0x")
+	for i := 0; i < len(bits); i += 4 {
+		nibble, err := strconv.ParseUint(bits[i:i+4], 2, 8)
+		if err != nil {
+			label.WriteString("?")
+			continue
+		}
+		fmt.Fprintf(&label, "%X", nibble)
 	}
-	canvas.Line(x0, y0, x1, y1, style)
+	return label.String(), true
+}
+
+// isBusValue reports whether a value should be rendered as a bus rather
+// than a single-bit wire.
+func isBusValue(val string) bool {
+	return len(val) > 1 || (val != "0" && val != "1")
+}
+
+// isUnknownBit reports whether a value is the unknown level "x" of a
+// single-bit wire.
+func isUnknownBit(val string) bool {
+	return val == "x" || val == "X"
+}
+
+// isTristateBit reports whether a value is the high-impedance level "z"
+// of a single-bit wire.
+func isTristateBit(val string) bool {
+	return val == "z" || val == "Z"
+}
+
+// isBus reports whether a value of the given signal is drawn as a bus. An
+// "x" or "z" is drawn as the unknown or high-impedance level of a wire,
+// unless the signal is declared wider than one bit, where it stands for a
+// bus of unknown bits. The values of a real variable are always drawn as a
+// bus, even those such as "0" that look like bits.
+func (v *VcdData) isBus(sig, val string) bool {
+	if v.isReal(sig) {
+		return true
+	}
+	if (isUnknownBit(val) || isTristateBit(val)) && v.Widths[sig] <= 1 {
+		return false
+	}
+	return isBusValue(val)
+}
+
+// isReal reports whether a signal was declared as a real variable, whose
+// values are numbers such as "2.5" or "0" rather than bits.
+func (v *VcdData) isReal(sig string) bool {
+	return v.Types[sig] == "real"
+}
+
+// value returns the value of a signal in effect at time t, or an empty
+// string if it has not been assigned by then.
+func (r *renderer) value(sig string, t uint64) string {
+	val, _ := ValueAt(r.changes[sig], t)
+	return val
+}
+
+// isBus reports whether a value of the given signal is drawn as a bus.
+func (r *renderer) isBus(sig, val string) bool {
+	return r.vcdData.isBus(sig, val)
+}
+
+// labelExtent returns the rightmost x coordinate reached by any bus label,
+// so that the canvas can reserve enough trailing room for the final value.
+func (r *renderer) labelExtent() int {
+	extent := 0
+	for _, sig := range r.signals {
+		for i := range r.times {
+			val := r.value(sig, r.times[i])
+			if !r.isBus(sig, val) {
+				continue
+			}
+			x := r.timeX(r.times[max(i-1, 0)]) + 1
+			extent = max(extent, x+len(r.fitLabel(sig, r.valueLabel(sig, val), 0))*busCharWidth)
+		}
+	}
+	return extent
+}
+
+// drawBusLabel draws the label of a bus value, abbreviating it to
+// MaxBusLabelChars with the full label as a tooltip when it is too long.
+func (r *renderer) drawBusLabel(x, y int, label, style string) {
+	limit := r.opts.MaxBusLabelChars
+	if limit <= 0 || utf8.RuneCountInString(label) <= limit {
+		r.canvas.Text(x, y, label, style)
+		return
+	}
+	r.canvas.Textspan(x, y, abbreviateMiddle(label, limit), style)
+	r.canvas.Title(label)
+	r.canvas.TextEnd()
+}
+
+// abbreviateMiddle shortens a label to at most n characters by replacing
+// its middle with an ellipsis, e.g. "0x1234ABCDEF" becomes "0x12…DEF".
+func abbreviateMiddle(label string, n int) string {
+	runes := []rune(label)
+	if len(runes) <= n {
+		return label
+	}
+	if n <= 1 {
+		return "…"
+	}
+	tail := (n - 1) / 2
+	head := n - 1 - tail
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
+// signalID returns an XML id for the group containing a signal's drawing.
+// Characters that are not letters, digits, '-' or '_' are replaced with '-',
+// and a numeric suffix is added if the id has already been used.
+func signalID(name string, used map[string]bool) string {
+	id := "signal-" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+
+	unique := id
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// drawSamplePoints draws a dot on the row of sig at every rising edge of the
+// clock signal. The value latched is the one held just before the edge.
+func (r *renderer) drawSamplePoints(sig string, y int) {
+	canvas, theme, times, clock := r.canvas, r.theme, r.times, r.opts.SamplePoints
+	for i := 1; i < len(times); i++ {
+		if r.value(clock, times[i-1]) != "0" || r.value(clock, times[i]) != "1" {
+			continue
+		}
+
+		x := r.timeX(times[i])
+		switch val := r.value(sig, times[i-1]); {
+		case val == "1":
+			canvas.Circle(x, y, 3, theme.SampleHigh)
+		case val == "0":
+			canvas.Circle(x, y+r.signalHeight, 3, theme.SampleLow)
+		case val != "":
+			canvas.Circle(x, y+(3*r.signalHeight/8), 3, theme.SampleBus)
+		}
+	}
+}
+
+// RenderOptions controls optional behaviour of the SVG renderer. The zero value
+// renders the same diagram as DrawSVG.
+type RenderOptions struct {
+	// SignalHeight, SignalGap, StepWidth and LeftMargin set the dimensions
+	// of the diagram in pixels: the height of each signal row, the space
+	// between rows, the width of one time unit and the room left for the
+	// signal names. Zero or negative values use the defaults.
+	SignalHeight int
+	SignalGap    int
+	StepWidth    int
+	LeftMargin   int
+
+	// MaxWidth, when positive, narrows the time steps so that the diagram
+	// is no wider than this many pixels, down to steps one pixel wide. The
+	// time labels are thinned out so that they do not overlap.
+	MaxWidth int
+
+	// DualTimeLabels labels each sampled tick with its sample ordinal above
+	// its absolute simulation time, which disambiguates views where the
+	// column position no longer matches the raw time.
+	DualTimeLabels bool
+
+	// RenameSignals maps signal names, as produced by ProcessVcd, to the names
+	// that should be rendered instead. Unknown source names are ignored.
+	RenameSignals map[string]string
+
+	// SignalLabels maps signal names to shorter labels drawn in the left
+	// margin in place of the name. Unlike RenameSignals the data, and every
+	// other option, still refers to the signal by its name.
+	SignalLabels map[string]string
+
+	// ValueColors maps a signal name to a mapping of bus value to fill colour,
+	// giving each distinct state of a bus its own background. Values without
+	// a mapping use the default bus fill.
+	ValueColors map[string]map[string]string
+
+	// GatedClockDetection shades the intervals in which a signal detected as
+	// a clock stops toggling for longer than its typical period.
+	GatedClockDetection bool
+
+	// SamplePoints names a clock signal. When set, a dot is drawn on every
+	// other signal at each rising edge of the clock, coloured by the level
+	// latched at that edge.
+	SamplePoints string
+
+	// MarkClockEdges draws a small triangle beneath every rising edge of
+	// each signal detected as a clock, i.e. a single-bit signal toggling
+	// at a regular period.
+	MarkClockEdges bool
+
+	// SignalGroups gathers the signals matching each group under a labelled
+	// section header, in the order of the groups. A signal belongs to the
+	// first group it matches, and signals matching no group are drawn
+	// first, outside of any section.
+	SignalGroups []GroupSpec
+
+	// ScopeTree arranges the signals into the tree of modules they were
+	// declared in, with an indented header for each scope and each label
+	// indented beneath it. It is ignored when SignalGroups are given.
+	ScopeTree bool
+
+	// AutoGroupBitSignals combines single-bit signals named "bus[0]",
+	// "bus[1]"... into a single bus row, ordered by bit index.
+	AutoGroupBitSignals bool
+
+	// StartTime and EndTime, when either is non-zero, draw only the part of
+	// the simulation between them, inclusive, in raw simulation time. The
+	// values in effect at StartTime are drawn from its start, and an
+	// EndTime of zero draws up to the end of the simulation.
+	StartTime uint64
+	EndTime   uint64
+
+	// ResampleInterval, when non-zero, snaps the simulation onto a uniform
+	// grid of this many time units before rendering, taking the value in
+	// effect at each grid point.
+	ResampleInterval uint64
+
+	// Theme sets the styles used to draw the diagram. A nil theme uses
+	// DefaultTheme, and LightTheme suits printed documentation.
+	Theme *Theme
+
+	// HighlightChanges, when set, shades the rows of the signals whose value
+	// at the To cursor differs from their value at the From cursor.
+	HighlightChanges *ChangeHighlight
+
+	// DontCareValues lists raw values that represent a "don't care" state.
+	// Intervals holding one of these values are drawn as a neutral band
+	// across the signal instead of as a level or bus value.
+	DontCareValues []string
+
+	// Descriptions maps signal names to human readable descriptions, which
+	// are attached to the signal's label as a tooltip.
+	Descriptions map[string]string
+
+	// DescriptionSubtitles additionally draws each description in small
+	// text beneath the signal's label.
+	DescriptionSubtitles bool
+
+	// RealPrecision sets the number of decimal places shown for real valued
+	// signals. Zero uses a default of three places, and a negative value
+	// shows the fewest digits that represent the value exactly.
+	RealPrecision int
+
+	// BusRadix labels every binary bus value in the selected base,
+	// whatever its width. Values with unknown bits stay in binary. The
+	// zero value, RadixAuto, abbreviates only long values to hexadecimal.
+	BusRadix Radix
+
+	// SignalRadix overrides BusRadix for the named signals, keyed by the
+	// names in VcdData.Signals.
+	SignalRadix map[string]Radix
+
+	// BusLabelFormat, when set, selects the text drawn in the bus boxes of
+	// the signals without a SignalRadix, in place of BusRadix.
+	BusLabelFormat BusLabelFormat
+
+	// MaxBusLabelChars, when positive, caps the number of characters shown
+	// in a bus label. Longer labels are abbreviated with an ellipsis in the
+	// middle and carry the full value as a tooltip.
+	MaxBusLabelChars int
+
+	// AnalogSignals draws the named buses as a staircase whose height
+	// follows the numeric value of the bus, scaled between the smallest
+	// and largest values it holds, in place of labelled segments. The
+	// staircase is broken wherever the value has unknown bits.
+	AnalogSignals map[string]bool
+
+	// CollapseConstantBuses draws a bus that never changes as one
+	// uninterrupted band with a single centered label.
+	CollapseConstantBuses bool
+
+	// PowerGroundDetection, when set, draws signals detected as power or
+	// ground rails as a thin labelled line instead of a waveform.
+	PowerGroundDetection *PowerGroundDetection
+
+	// ScopeDisplay selects how much of each signal's scope path is shown in
+	// its label, by default only the innermost scope, and ScopeDepth the
+	// number of scopes kept by ScopeLastN.
+	ScopeDisplay ScopeDisplayMode
+	ScopeDepth   int
+
+	// ShowSegmentDurations labels each stable segment of a signal with the
+	// number of time units its value was held for.
+	ShowSegmentDurations bool
+
+	// MirrorVertical lays the diagram out upside down, with the time axis at
+	// the bottom and the signals stacked upward from it, so that the diagram
+	// can be placed above another trace to compare the two about a shared
+	// axis. The waveforms themselves are not inverted.
+	MirrorVertical bool
+
+	// InitialValues selects the value shown for a signal before its first
+	// change. By default the signal is drawn without a value.
+	InitialValues InitialValueMode
+
+	// ShowActivityRuler draws a strip beneath the time axis with a bar for
+	// each fixed-width stretch of the diagram, proportional to the number of
+	// transitions within it.
+	ShowActivityRuler bool
+
+	// RenderDelay shifts the drawn transitions of the named signals to the
+	// right by the given number of pixels, to illustrate propagation delay.
+	// The underlying data is unchanged, and transitions are never moved past
+	// the end of the trace.
+	RenderDelay map[string]int
+
+	// HighColor and LowColor, when set, colour the portions of scalar wires
+	// at the high and low levels, in place of the theme's wire colour. Each
+	// transition is drawn half in the colour of each level it joins.
+	HighColor string
+	LowColor  string
+
+	// SignalColors maps a signal name to a CSS colour that its wire levels,
+	// transitions and bus outline are drawn in, in place of the theme's wire
+	// and bus colours and of HighColor and LowColor.
+	SignalColors map[string]string
+
+	// PathRendering draws the wires and bus outlines of each signal as one
+	// path per style, rather than a line element for every segment, which
+	// greatly reduces the size of long traces.
+	PathRendering bool
+
+	// Shadow configures the shadow drawn beneath wires and bus outlines. By
+	// default a 1px shadow in the theme's shadow style is drawn.
+	Shadow *ShadowOptions
+
+	// ShowPortDirections draws an arrow beside the label of each port
+	// signal showing its direction, taken from the data's Directions or
+	// guessed from the signal's name.
+	ShowPortDirections bool
+
+	// TimeRounding controls how the times shown in labels are rounded. It
+	// does not affect where anything is drawn.
+	TimeRounding TimeRounding
+
+	// TimeOffset is added to every time shown in a label, for traces that
+	// are a capture starting part way through a longer run. It does not
+	// affect where anything is drawn.
+	TimeOffset uint64
+
+	// BitOrder controls whether bus values are read MSB-first or LSB-first
+	// before being shown in hexadecimal. By default the declared range of
+	// each bus decides.
+	BitOrder BitOrder
+
+	// PinnedSignals are kept in view at the top of the HTML export while the
+	// other signals scroll beneath them.
+	PinnedSignals []string
+
+	// Transactions are labelled regions drawn over the rows of several
+	// signals for a span of time.
+	Transactions []Transaction
+
+	// Cursors mark times of interest with a labelled vertical line drawn
+	// over the signals.
+	Cursors []Cursor
+
+	// ShowComments draws the text of the comments found among the value
+	// changes beneath the diagram, at the time each appears.
+	ShowComments bool
+
+	// BitRaster draws a compact raster in place of the waveforms, for
+	// traces with hundreds of single-bit signals: each signal is a row one
+	// pixel tall and each time unit a column one pixel wide, coloured by
+	// the level of the signal. No labels, axis or annotations are drawn.
+	BitRaster bool
+
+	// PageLength, when non-zero, is the number of time units shown on each
+	// page drawn by DrawSVGPages.
+	PageLength uint64
+
+	// SignalOrder selects the order in which the signals are drawn, by
+	// default sorted by name. CustomSignalOrder lists the signals drawn
+	// first under SignalOrderCustom, the others following in name order.
+	SignalOrder       SignalOrder
+	CustomSignalOrder []string
+
+	// MaxSignalsPerPage, when positive, is the number of signals shown on
+	// each page drawn by DrawSVGSignalPages.
+	MaxSignalsPerPage int
+
+	// Workers sets the number of goroutines drawing the signal rows
+	// concurrently, which speeds up traces with many signals. Values below
+	// two draw the rows one after another. The output is the same either
+	// way.
+	Workers int
+
+	// PNGScale multiplies the size of the image drawn by the "png" output
+	// format, e.g. 2 for displays with twice the usual pixel density. Zero
+	// draws the image at the size of the SVG.
+	PNGScale float64
+
+	// Sidebar, when set, adds a panel beside the diagram listing each
+	// signal's value at a cursor time next to a colour swatch.
+	Sidebar *Sidebar
+}
+
+// ShadowOptions configures the shadow of wires and bus outlines.
+type ShadowOptions struct {
+	// Disabled draws the lines without any shadow.
+	Disabled bool
+	// Offset is the distance in pixels of the shadow from its line,
+	// defaulting to 1.
+	Offset int
+	// Color and Opacity replace the theme's shadow style when Color is
+	// set. An Opacity of zero defaults to 0.5.
+	Color   string
+	Opacity float64
+}
+
+// Transaction marks a span of time across a set of signals, such as a bus
+// transfer, with a label.
+type Transaction struct {
+	Start, End uint64
+	Signals    []string
+	Label      string
+}
+
+// ChangeHighlight selects the pair of cursor times compared when
+// highlighting the signals affected between them.
+type ChangeHighlight struct {
+	From uint64
+	To   uint64
 }
 
 // DrawSVG generates an SVG waveform visualization from simulation data.
@@ -64,117 +604,955 @@ func drawLineWithShadow(canvas *svg.SVG, x0 int, y0 int, x1 int, y1 int, style s
 // is indexed by signal name, and a list of signal names to be displayed.
 // Returns the SVG as a byte slice.
 func DrawSVG(vcdData *VcdData) []byte {
+	return DrawSVGWithOptions(vcdData, RenderOptions{})
+}
+
+// DrawSVGWithOptions generates an SVG waveform visualization from simulation data
+// using the provided render options. Returns the SVG as a byte slice.
+//
+// The diagram is drawn in a fixed sequence of phases so that the document
+// order of its elements does not depend on which options are enabled:
+// background, grid, axis, signal groups, annotations, cursors and finally
+// the legend. Each feature draws within its phase, and later phases are
+// painted on top of earlier ones.
+func DrawSVGWithOptions(vcdData *VcdData, opts RenderOptions) []byte {
 	var out bytes.Buffer
-	sim := vcdData.Sim
-	signals := vcdData.Signals
-	outputBuffer := bufio.NewWriter(&out)
+	DrawSVGTo(&out, vcdData, opts)
+	return out.Bytes()
+}
+
+// DrawSVGTo renders the same diagram as DrawSVGWithOptions, writing it to w
+// as it is drawn rather than returning it, so that it can be streamed to a
+// file or an HTTP response. The first error returned by w is returned.
+func DrawSVGTo(w io.Writer, vcdData *VcdData, opts RenderOptions) error {
+	return drawSVGTo(w, prepareData(vcdData, opts), opts, false)
+}
+
+// drawSVG renders the diagram of data already prepared by prepareData,
+// annotating its elements with the data used by the scripts of the HTML
+// export when interactive is set.
+func drawSVG(vcdData *VcdData, opts RenderOptions, interactive bool) []byte {
+	var out bytes.Buffer
+	drawSVGTo(&out, vcdData, opts, interactive)
+	return out.Bytes()
+}
+
+// drawSVGTo renders the diagram like drawSVG, writing it to w. The canvas
+// ignores write errors, so the diagram is written through a buffer, which
+// keeps the first error and reports it once the diagram is flushed.
+func drawSVGTo(w io.Writer, vcdData *VcdData, opts RenderOptions, interactive bool) error {
+	outputBuffer := bufio.NewWriter(w)
+
+	r := newRenderer(svg.New(outputBuffer), vcdData, opts)
+	r.interactive = interactive
+	r.draw()
+
+	return outputBuffer.Flush()
+}
+
+// draw renders the whole diagram onto the renderer's canvas.
+func (r *renderer) draw() {
+	if r.opts.BitRaster {
+		r.drawBitRaster()
+		return
+	}
+	r.canvas.Start(r.width, r.height)
+	r.drawBackground()
+
+	// a sidebar on the left moves the diagram across to make room for it
+	leftSidebar := r.opts.Sidebar != nil && r.opts.Sidebar.Left
+	if leftSidebar {
+		r.canvas.Translate(r.sidebar, 0)
+	}
+	for _, phase := range []func(){
+		r.drawGrid,
+		r.drawAxis,
+		r.drawSections,
+		r.drawSignals,
+		r.drawTransactions,
+		r.drawCursors,
+		r.drawComments,
+		r.drawActivityRuler,
+	} {
+		phase()
+	}
+	if leftSidebar {
+		r.canvas.Gend()
+	}
+	r.drawSidebar()
+	r.canvas.End()
+}
+
+// Dimensions returns the width and height of the SVG that DrawSVGWithOptions
+// would produce for the given data and options, without rendering it.
+func Dimensions(vcdData *VcdData, opts RenderOptions) (int, int) {
+	r := newRenderer(nil, prepareData(vcdData, opts), opts)
+	return r.width, r.height
+}
+
+// window returns the part of the simulation between StartTime and EndTime,
+// or the whole simulation when neither is set.
+func (o RenderOptions) window(vcdData *VcdData) *VcdData {
+	if (o.StartTime == 0 && o.EndTime == 0) || len(vcdData.Times) == 0 {
+		return vcdData
+	}
+	end := o.EndTime
+	if end == 0 {
+		end = vcdData.Times[len(vcdData.Times)-1]
+	}
+	return vcdData.Clip(o.StartTime, max(end, o.StartTime))
+}
+
+// prepareData applies the data transformations requested by the render
+// options before the diagram is laid out.
+func prepareData(vcdData *VcdData, opts RenderOptions) *VcdData {
+	if len(opts.RenameSignals) > 0 {
+		vcdData = vcdData.Rename(opts.RenameSignals)
+	}
+	if opts.BitOrder != BitOrderMSBFirst {
+		vcdData = vcdData.ApplyBitOrder(opts.BitOrder)
+	}
+	if opts.InitialValues != InitialAbsent {
+		vcdData = vcdData.SeedInitialValues(opts.InitialValues)
+	}
+	if opts.AutoGroupBitSignals {
+		vcdData = vcdData.GroupBitSignals()
+	}
+	vcdData = opts.window(vcdData)
+	if opts.ResampleInterval > 0 {
+		vcdData = vcdData.Resample(opts.ResampleInterval)
+	}
+	switch opts.SignalOrder {
+	case SignalOrderDeclared:
+		vcdData = vcdData.OrderSignals(vcdData.DeclOrder)
+	case SignalOrderCustom:
+		vcdData = vcdData.OrderSignals(opts.CustomSignalOrder)
+	}
+	return vcdData
+}
+
+// layout holds the dimensions of a diagram, in pixels.
+type layout struct {
+	signalHeight int
+	signalGap    int
+	stepWidth    int
+	leftMargin   int
+}
+
+// layout returns the dimensions requested by the options, falling back to
+// the defaults for any that are not positive.
+func (o RenderOptions) layout() layout {
+	orDefault := func(v, def int) int {
+		if v <= 0 {
+			return def
+		}
+		return v
+	}
+	return layout{
+		signalHeight: orDefault(o.SignalHeight, signalHeight),
+		signalGap:    orDefault(o.SignalGap, signalGap),
+		stepWidth:    orDefault(o.StepWidth, stepWidth),
+		leftMargin:   orDefault(o.LeftMargin, leftMargin),
+	}
+}
+
+// renderer holds the state shared by the drawing phases of a single diagram.
+type renderer struct {
+	layout
+
+	canvas  *svg.SVG
+	opts    RenderOptions
+	theme   *Theme
+	vcdData *VcdData
+	changes map[string][]Change
+	signals []string
+	times   []uint64
+	width   int
+	height  int
+
+	// interactive adds the data attributes used by the HTML export
+	interactive bool
+
+	// thumbnail leaves out the signal names and bus values
+	thumbnail bool
+
+	// continued marks a page whose values carry over from an earlier page
+	continued bool
+
+	// shadow is the style of line shadows, or empty when they are disabled,
+	// and shadowOffset their distance from the line
+	shadow       string
+	shadowOffset int
+
+	// sidebar is the width reserved for the sidebar panel, or zero when
+	// there is none
+	sidebar int
+
+	// ruler is the height reserved for the activity ruler beneath the time
+	// axis, or zero when there is none
+	ruler int
+
+	// scalarOnly is set when every value in the trace is "0" or "1", so
+	// the wires can be drawn without the checks needed for other values
+	scalarOnly bool
+
+	// slots holds the row each signal is drawn in when the rows are
+	// divided into sections, which take up rows of their own
+	slots    []int
+	sections []section
+
+	// depths holds the depth of each signal within the scope tree, by
+	// which its label is indented
+	depths map[string]int
+}
+
+// newRenderer prepares the layout of the diagram for the given data.
+func newRenderer(canvas *svg.SVG, vcdData *VcdData, opts RenderOptions) *renderer {
+	// a trace without any simulation is drawn like one whose signals are
+	// unassigned at time zero, as an empty axis beside the signal labels
+	if len(vcdData.Times) == 0 {
+		empty := *vcdData
+		empty.Times = []uint64{0}
+		vcdData = &empty
+	}
+	r := &renderer{
+		layout:  opts.layout(),
+		canvas:  canvas,
+		opts:    opts,
+		theme:   opts.Theme,
+		vcdData: vcdData,
+		changes: vcdData.Changes,
+		signals: vcdData.Signals,
+		times:   vcdData.Times,
+	}
+	if r.theme == nil {
+		r.theme = DefaultTheme()
+	}
+
+	r.shadow, r.shadowOffset = r.theme.Shadow, 1
+	if shadow := opts.Shadow; shadow != nil {
+		if shadow.Offset != 0 {
+			r.shadowOffset = shadow.Offset
+		}
+		if shadow.Color != "" {
+			opacity := shadow.Opacity
+			if opacity == 0 {
+				opacity = 0.5
+			}
+			r.shadow = fmt.Sprintf(shadowColorStyle, shadow.Color, opacity)
+		}
+		if shadow.Disabled {
+			r.shadow = ""
+		}
+	}
+
+	r.scalarOnly = len(opts.DontCareValues) == 0 && !slices.ContainsFunc(r.signals, vcdData.isReal) &&
+		isScalarOnly(r.changes, r.signals, r.times)
+	if len(opts.SignalGroups) > 0 {
+		r.signals, r.slots, r.sections = groupSections(r.signals, opts.SignalGroups)
+	} else if opts.ScopeTree {
+		r.signals, r.slots, r.sections, r.depths = scopeSections(r.signals, vcdData.Scopes)
+	}
+
+	if opts.Sidebar != nil {
+		r.sidebar = sidebarWidth
+	}
+	r.width = r.layoutWidth()
+	if opts.MaxWidth > 0 && r.width > opts.MaxWidth && len(r.times) > 0 {
+		r.stepWidth = max((opts.MaxWidth-r.leftMargin-rightMargin-r.sidebar)/len(r.times), 1)
+		r.width = r.layoutWidth()
+	}
+	if opts.ShowActivityRuler {
+		r.ruler = activityHeight + 5
+	}
+	r.height = (len(r.signals)+len(r.sections))*(r.signalHeight+r.signalGap) + 100 + r.ruler
+	if opts.BitRaster {
+		r.width, r.height = rasterSize(r.times, len(r.signals))
+	}
+	return r
+}
+
+// layoutWidth returns the width of the diagram at the current step width,
+// leaving trailing room so the final bus label is never clipped.
+func (r *renderer) layoutWidth() int {
+	width := len(r.times)*r.stepWidth + r.leftMargin + rightMargin
+	return max(width, r.labelExtent()+rightMargin) + r.sidebar
+}
+
+// flipY returns the y coordinate of an element of the given height whose
+// top is at y, moving it to the mirrored position when MirrorVertical is
+// set. Text is given a negative height, as it extends above its baseline.
+func (r *renderer) flipY(y, height int) int {
+	if !r.opts.MirrorVertical {
+		return y
+	}
+	return r.height - y - height
+}
+
+// newPen returns a pen drawing on the canvas with the configured shadow and
+// line rendering.
+func (r *renderer) newPen() *pen {
+	p := newPen(r.canvas, r.opts.PathRendering)
+	p.shadow, p.shadowOffset = r.shadow, r.shadowOffset
+	return p
+}
+
+// rowY returns the y coordinate of the top of the i-th signal row.
+func (r *renderer) rowY(i int) int {
+	if r.slots != nil {
+		return r.slotY(r.slots[i])
+	}
+	return r.slotY(i)
+}
+
+// slotY returns the y coordinate of the top of the given row of the
+// diagram, counting both signal rows and section headers.
+func (r *renderer) slotY(slot int) int {
+	return r.flipY(50+r.ruler+slot*(r.signalHeight+r.signalGap), r.signalHeight)
+}
 
-	width := len(sim)*stepWidth + leftMargin + 10
-	height := len(signals)*(signalHeight+signalGap) + 100
+// drawBackground fills the whole canvas with the background colour.
+func (r *renderer) drawBackground() {
+	r.canvas.Rect(0, 0, r.width, r.height, r.theme.Background)
+}
 
-	canvas := svg.New(outputBuffer)
-	canvas.Start(width, height)
-	canvas.Rect(0, 0, width, height, backgroundStyle)
+// timeX returns the x coordinate of the given time. Every time in the
+// trace has a column of its own, so a long gap between value changes takes
+// no more room than a short one. A time between two columns is placed in
+// proportion between them, and one outside the trace a step per time unit
+// beyond its nearest column.
+func (r *renderer) timeX(t uint64) int {
+	i, found := slices.BinarySearch(r.times, t)
+	column := float64(i)
+	switch {
+	case found:
+	case i == 0:
+		column = -float64(r.times[0] - t)
+	case i == len(r.times):
+		column = float64(i-1) + float64(t-r.times[i-1])
+	default:
+		prev, next := r.times[i-1], r.times[i]
+		column = float64(i-1) + float64(t-prev)/float64(next-prev)
+	}
+	return int(math.Round(column*float64(r.stepWidth))) + r.leftMargin
+}
 
-	// Sort time steps
-	times := make([]uint64, 0, len(sim))
-	for t := range sim {
-		times = append(times, t)
+// drawGrid draws a vertical dotted line for every time step after the
+// first.
+func (r *renderer) drawGrid() {
+	for _, t := range r.times[1:] {
+		x := r.timeX(t)
+		r.canvas.Line(x, r.flipY(40, 0), x, r.flipY(r.height-30, 0), r.theme.Grid)
 	}
-	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+}
+
+// drawAxis draws the axis at the first time along with the tick and time
+// label of every time step.
+func (r *renderer) drawAxis() {
+	r.canvas.Line(r.leftMargin, r.flipY(40, 0), r.leftMargin, r.flipY(r.height-30, 0), r.theme.Axis)
 
-	// Determine the maximum time
-	maxTime := times[len(times)-1]
+	every := r.labelInterval()
+	for i, t := range r.times {
+		x := r.timeX(t)
 
-	// Add vertical dotted grid lines and time markers
-	gridTop := 40
-	gridBottom := height - 30
-	for t := 0; t <= int(maxTime); t++ {
-		x := t*stepWidth + leftMargin
-		strokeStyle := gridStyle
-		if t == 0 {
-			strokeStyle = axisStyle
+		// Draw tick and label at the top, leaving out the labels that
+		// would overlap when the steps are narrow
+		r.canvas.Line(x, r.flipY(35, 0), x, r.flipY(45, 0), r.theme.Tick)
+		if i%every != 0 {
+			continue
 		}
-		canvas.Line(x, gridTop, x, gridBottom, strokeStyle)
+		r.canvas.Text(x, r.flipY(30, -10), r.timeLabel(t), r.theme.TickText)
 
-		// Draw tick and label at the top
-		canvas.Line(x, 35, x, 45, tickStyle)
-		canvas.Text(x, 30, fmt.Sprintf("%d", t), tickTextStyle)
+		// Draw the sample ordinal above the time label
+		if r.opts.DualTimeLabels {
+			r.canvas.Text(x, r.flipY(18, -10), fmt.Sprintf("#%d", i), r.theme.TickText)
+		}
 	}
+}
+
+// labelInterval returns how many ticks apart the time labels are drawn, so
+// that labels are at least minLabelSpacing pixels apart, and a glyph
+// further apart than the widest label. The labels of the first and last
+// times stand in for the widest. The interval is a round number of ticks:
+// 1, 2 or 5 times a power of ten.
+func (r *renderer) labelInterval() int {
+	widest := max(len(r.timeLabel(r.times[0])), len(r.timeLabel(r.times[len(r.times)-1])))
+	spacing := max(minLabelSpacing, (widest+1)*busCharWidth)
+	return niceInterval((spacing + r.stepWidth - 1) / r.stepWidth)
+}
+
+// niceInterval returns the smallest of 1, 2, 5, 10, 20, 50... that is at
+// least n.
+func niceInterval(n int) int {
+	for scale := 1; ; scale *= 10 {
+		for _, step := range []int{1, 2, 5} {
+			if step*scale >= n {
+				return step * scale
+			}
+		}
+	}
+}
+
+// timeLabel formats a simulation time for display, shifted by the
+// TimeOffset, scaled to the timescale of the trace with its unit, and
+// rounded as requested.
+func (r *renderer) timeLabel(t uint64) string {
+	timescale := r.vcdData.Timescale
+	scaled := (t + r.opts.TimeOffset) * max(timescale.Magnitude, 1)
+	return formatTimeLabel(float64(scaled), timescale.Unit, r.opts.TimeRounding)
+}
+
+// durationLabel formats a length of simulation time for display like
+// timeLabel, scaled to the timescale of the trace with its unit, but
+// without the TimeOffset.
+func (r *renderer) durationLabel(d uint64) string {
+	timescale := r.vcdData.Timescale
+	scaled := d * max(timescale.Magnitude, 1)
+	return formatTimeLabel(float64(scaled), timescale.Unit, r.opts.TimeRounding)
+}
+
+// labelName returns the name a signal is labelled with.
+func (r *renderer) labelName(sig string) string {
+	if label, ok := r.opts.SignalLabels[sig]; ok {
+		return label
+	}
+	if r.depths != nil {
+		// the scope is shown by the headers of the tree
+		return r.vcdData.DisplayName(sig, ScopeLeafOnly, 0)
+	}
+	return r.vcdData.DisplayName(sig, r.opts.ScopeDisplay, r.opts.ScopeDepth)
+}
 
-	y := 50
+// drawLabel draws the name of a signal in the left margin, carrying its
+// description as a tooltip when one is available.
+func (r *renderer) drawLabel(sig string, y int) {
+	name := r.labelName(sig)
+	x := 10
+	if r.depths != nil {
+		x += r.depths[sig] * scopeIndent
+	}
+	if glyph := r.vcdData.portDirection(sig).glyph(); r.opts.ShowPortDirections && glyph != "" {
+		r.canvas.Text(r.leftMargin-8, y+r.signalHeight/2, glyph, r.theme.Direction)
+	}
+	description, ok := r.opts.Descriptions[sig]
+	if !ok {
+		r.canvas.Text(x, y+r.signalHeight/2, name, r.theme.Text)
+		return
+	}
+
+	r.canvas.Textspan(x, y+r.signalHeight/2, name, r.theme.Text)
+	r.canvas.Title(description)
+	r.canvas.TextEnd()
+	if r.opts.DescriptionSubtitles {
+		r.canvas.Text(x, y+r.signalHeight, description, r.theme.Subtitle)
+	}
+}
+
+// drawSignals draws each signal within its own group, along with any
+// decorations that belong to that signal's row. When Workers is above one
+// the rows are drawn concurrently, each into its own buffer, and written
+// out in order once all are done.
+func (r *renderer) drawSignals() {
+	// Determine which signals changed between the highlight cursors
+	var before, after map[string]string
+	if r.opts.HighlightChanges != nil {
+		before = r.vcdData.SnapshotAt(r.opts.HighlightChanges.From)
+		after = r.vcdData.SnapshotAt(r.opts.HighlightChanges.To)
+	}
+
+	// the ids depend on the signals before them, so they are assigned first
+	usedIDs := map[string]bool{}
+	ids := make([]string, len(r.signals))
+	for i, sig := range r.signals {
+		ids[i] = signalID(sig, usedIDs)
+	}
+	draw := func(canvas *svg.SVG, i int) {
+		row := *r
+		row.canvas = canvas
+		sig := r.signals[i]
+		if color, ok := r.opts.SignalColors[sig]; ok {
+			row.theme = r.theme.withSignalColor(color)
+			row.opts.HighColor, row.opts.LowColor = "", ""
+		}
+		row.drawSignal(i, ids[i], r.opts.HighlightChanges != nil && before[sig] != after[sig])
+	}
+
+	workers := min(r.opts.Workers, len(r.signals))
+	if workers <= 1 {
+		for i := range r.signals {
+			draw(r.canvas, i)
+		}
+		return
+	}
+
+	fragments := make([]bytes.Buffer, len(r.signals))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				draw(svg.New(&fragments[i]), i)
+			}
+		}()
+	}
+	for i := range r.signals {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for i := range fragments {
+		r.canvas.Writer.Write(fragments[i].Bytes())
+	}
+}
+
+// drawSignal draws the row of the i-th signal within a group with the
+// given id, shading it when highlighted is set.
+func (r *renderer) drawSignal(i int, id string, highlighted bool) {
+	canvas := r.canvas
+	theme := r.theme
+	sig := r.signals[i]
+	y := r.rowY(i)
+
+	canvas.Gid(id)
+	if highlighted {
+		canvas.Rect(0, y-r.signalGap/2, r.width-r.sidebar, r.signalHeight+r.signalGap, theme.Highlight)
+	}
+	if !r.thumbnail {
+		r.drawLabel(sig, y)
+	}
+
+	if r.opts.GatedClockDetection {
+		for _, span := range gatedClockSpans(r.changes[sig], r.times) {
+			x0 := r.timeX(span.Start)
+			x1 := r.timeX(span.End)
+			canvas.Rect(x0, y, x1-x0, r.signalHeight, theme.Gated)
+		}
+	}
+
+	if level, ok := r.railLevel(sig); ok {
+		r.drawRail(level, y)
+	} else if r.opts.AnalogSignals[sig] {
+		r.drawAnalog(sig, y)
+	} else if r.opts.CollapseConstantBuses && r.isConstantBus(sig) {
+		r.drawConstantBus(sig, y)
+	} else if r.scalarOnly {
+		r.drawScalarWaveform(sig, y)
+	} else {
+		r.drawWaveform(sig, y)
+	}
+
+	if r.opts.ShowSegmentDurations {
+		r.drawSegmentDurations(sig, y)
+	}
+
+	if r.opts.MarkClockEdges {
+		r.drawClockEdges(sig, y)
+	}
+
+	if r.opts.SamplePoints != "" && sig != r.opts.SamplePoints {
+		r.drawSamplePoints(sig, y)
+	}
+
+	if !r.thumbnail {
+		r.drawValueTooltips(sig, y)
+	}
+	canvas.Gend()
+}
+
+// isScalarOnly reports whether every signal holds "0" or "1" at every
+// time step.
+func isScalarOnly(changes map[string][]Change, signals []string, times []uint64) bool {
 	for _, sig := range signals {
-		canvas.Text(10, y+signalHeight/2, sig, textStyle)
-
-		var lastVal string
-		var lastX int
-		lastLabel := ""
-		for i, t := range times {
-			x := int(t)*stepWidth + leftMargin
-			val := sim[t][sig]
-
-			if i == 0 {
-				lastVal = val
-				lastX = x
-				continue
+		list := changes[sig]
+		if len(times) > 0 && (len(list) == 0 || list[0].Time > times[0]) {
+			return false
+		}
+		for _, c := range list {
+			if c.Value != "0" && c.Value != "1" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// drawScalarWaveform draws a wire in a trace where every value is "0" or
+// "1". It draws the same lines as drawWaveform, without its handling of
+// buses and other values, and unless the lines are gathered into paths
+// it writes their markup directly rather than through the canvas. The
+// styles come from the theme of the row, so a signal colour applies here
+// as it does on the general path.
+func (r *renderer) drawScalarWaveform(sig string, y int) {
+	p := r.newPen()
+	lines := scalarLines{pen: p}
+	if !p.paths {
+		lines.tails = map[string]string{}
+		defer func() { r.canvas.Writer.Write(lines.buf) }()
+	} else {
+		defer p.flush()
+	}
+
+	delay := r.opts.RenderDelay[sig]
+	blend := r.opts.HighColor != "" || r.opts.LowColor != ""
+	highStyle, lowStyle := r.levelStyle("1"), r.levelStyle("0")
+	endX := r.timeX(r.times[len(r.times)-1])
+	high, low := y, y+r.signalHeight
+	lastX := r.timeX(r.times[0])
+	lastHigh := r.value(sig, r.times[0]) == "1"
+	for _, t := range r.times[1:] {
+		x := min(r.timeX(t)+delay, endX)
+		isHigh := r.value(sig, t) == "1"
+
+		y0, y1, style0, style1 := low, low, lowStyle, lowStyle
+		if lastHigh {
+			y0, style0 = high, highStyle
+		}
+		if isHigh {
+			y1, style1 = high, highStyle
+		}
+		lines.lineWithShadow(lastX, y0, x, y0, style0)
+		if isHigh != lastHigh {
+			if blend {
+				mid := y + r.signalHeight/2
+				lines.lineWithShadow(x, y0, x, mid, style0)
+				lines.lineWithShadow(x, mid, x, y1, style1)
+			} else {
+				lines.lineWithShadow(x, y0, x, y1, r.theme.Wire)
+			}
+		}
+
+		lastX = x
+		lastHigh = isHigh
+	}
+}
+
+// scalarLines draws the lines of drawScalarWaveform. When the pen gathers
+// paths they are handed on to it; otherwise each line is appended to buf
+// as the same markup svgo would write, with the closing attributes of
+// each style built only once.
+type scalarLines struct {
+	pen   *pen
+	buf   []byte
+	tails map[string]string
+}
+
+// lineWithShadow draws a line and its shadow, as pen.lineWithShadow does.
+func (l *scalarLines) lineWithShadow(x0, y0, x1, y1 int, style string) {
+	if l.tails == nil {
+		l.pen.lineWithShadow(x0, y0, x1, y1, style)
+		return
+	}
+	p := l.pen
+	if p.shadow != "" && y0 == y1 {
+		l.line(x0, y0+p.shadowOffset, x1, y1+p.shadowOffset, p.shadow)
+	} else if p.shadow != "" {
+		l.line(x0+p.shadowOffset, y0, x1+p.shadowOffset, y1, p.shadow)
+	}
+	l.line(x0, y0, x1, y1, style)
+}
+
+// line appends a <line> element in the form written by svg.SVG.Line.
+func (l *scalarLines) line(x0, y0, x1, y1 int, style string) {
+	tail, ok := l.tails[style]
+	if !ok {
+		// svgo writes attributes given in place of a style unchanged
+		if strings.Index(style, "=") > 0 {
+			tail = style + " />\n"
+		} else if style != "" {
+			tail = `style="` + style + `" />` + "\n"
+		} else {
+			tail = " />\n"
+		}
+		l.tails[style] = tail
+	}
+	l.buf = append(l.buf, `<line x1="`...)
+	l.buf = strconv.AppendInt(l.buf, int64(x0), 10)
+	l.buf = append(l.buf, `" y1="`...)
+	l.buf = strconv.AppendInt(l.buf, int64(y0), 10)
+	l.buf = append(l.buf, `" x2="`...)
+	l.buf = strconv.AppendInt(l.buf, int64(x1), 10)
+	l.buf = append(l.buf, `" y2="`...)
+	l.buf = strconv.AppendInt(l.buf, int64(y1), 10)
+	l.buf = append(l.buf, `" `...)
+	l.buf = append(l.buf, tail...)
+}
+
+// drawWaveform draws the levels and bus segments of a signal across every
+// time step.
+func (r *renderer) drawWaveform(sig string, y int) {
+	canvas := r.canvas
+	theme := r.theme
+
+	var lastVal string
+	var lastX int
+	lastLabel := ""
+	p := r.newPen()
+	defer p.flush()
+	endX := r.timeX(r.times[len(r.times)-1])
+	for i, t := range r.times {
+		x := r.timeX(t)
+		if i > 0 {
+			x = min(x+r.opts.RenderDelay[sig], endX)
+		}
+		val := r.value(sig, t)
+
+		if i == 0 {
+			lastVal = val
+			lastX = x
+
+			// repeat the label of a bus segment carried over from the
+			// previous page so that its value is not lost at the split
+			if r.continued && r.isBus(sig, val) && !r.thumbnail {
+				lastLabel = r.valueLabel(sig, val)
+				r.drawBusLabel(x+1, y+(r.signalHeight/2), r.fitLabel(sig, lastLabel, r.runEnd(sig, 0)-x), theme.BusValue)
 			}
+			continue
+		}
 
-			isBus := len(val) > 1 || (val != "0" && val != "1")
+		if lastVal == "" {
+			// the signal has not been assigned yet, so it has no level
+			// and leaving it needs no edge
+			canvas.Rect(lastX, y, x-lastX, r.signalHeight, theme.Undefined)
+		} else if slices.Contains(r.opts.DontCareValues, lastVal) {
+			canvas.Rect(lastX, y, x-lastX, r.signalHeight, theme.DontCare)
+		} else if r.isBus(sig, val) {
+			yTop := y
+			yBottom := y + (3 * r.signalHeight / 4)
 
-			if isBus {
-				yTop := y
-				yBottom := y + (3 * signalHeight / 4)
+			// Fill area between bus lines, using the colour mapped to the held value
+			fillStyle := theme.BusFill
+			if color, ok := r.opts.ValueColors[sig][lastVal]; ok {
+				fillStyle = fmt.Sprintf(busColorStyle, color)
+			}
+			canvas.Polygon([]int{lastX, x, x, lastX}, []int{yTop, yTop, yBottom, yBottom}, r.busSegmentAttrs(sig, lastVal, fillStyle)...)
 
-				// Fill area between bus lines
-				canvas.Polygon([]int{lastX, x, x, lastX}, []int{yTop, yTop, yBottom, yBottom}, busFillStyle)
+			if val != lastVal && i > 1 {
+				// "X" crossing to denote change
+				p.lineWithShadow(lastX, yTop, x, yBottom, theme.Bus)
+				p.lineWithShadow(lastX, yBottom, x, yTop, theme.Bus)
 
+			} else {
+				// Draw double line for the bus. The first interval is
+				// always drawn level, so that the value the trace starts
+				// with is shown even when it changes at the next time,
+				// and such a change is marked by a short crossing at its
+				// end
+				p.lineWithShadow(lastX, yTop, x, yTop, theme.Bus)
+				p.lineWithShadow(lastX, yBottom, x, yBottom, theme.Bus)
+				cross := 0
 				if val != lastVal {
-					// "X" crossing to denote change
-					drawLineWithShadow(canvas, lastX, yTop, x, yBottom, busStyle)
-					drawLineWithShadow(canvas, lastX, yBottom, x, yTop, busStyle)
-
-				} else {
-					// Draw double line for the bus
-					drawLineWithShadow(canvas, lastX, yTop, x, yTop, busStyle)
-					drawLineWithShadow(canvas, lastX, yBottom, x, yBottom, busStyle)
-
-					// Display value in between lines
-					label := val
-					if len(label) > 8 {
-						bits := strings.TrimPrefix(label, "b")
-						if i, err := strconv.ParseUint(bits, 2, 64); err == nil {
-							label = fmt.Sprintf("0x%X", i)
-						}
-					}
-
-					if lastLabel != label {
-						canvas.Text(lastX+1, y+(signalHeight/2), label, busValueStyle)
-						lastLabel = label
-					}
+					cross = min(firstCrossWidth, (x-lastX)/2)
+					p.lineWithShadow(x-cross, yTop, x, yBottom, theme.Bus)
+					p.lineWithShadow(x-cross, yBottom, x, yTop, theme.Bus)
+				}
+
+				// Display value in between lines
+				label := r.valueLabel(sig, lastVal)
+				if lastLabel != label && !r.thumbnail {
+					r.drawBusLabel(lastX+1, y+(r.signalHeight/2), r.fitLabel(sig, label, r.runEnd(sig, i-1)-lastX-cross), theme.BusValue)
+					lastLabel = label
 				}
+			}
+		} else {
+			y0 := r.levelY(lastVal, y)
+			y1 := r.levelY(val, y)
+
+			if isUnknownBit(lastVal) {
+				// an unknown level is a band across the whole row, and
+				// leaving it needs no edge
+				canvas.Rect(lastX, y, x-lastX, r.signalHeight, theme.Unknown)
 			} else {
-				y0 := y + signalHeight
-				if lastVal == "1" {
-					y0 = y
+				p.lineWithShadow(lastX, y0, x, y0, r.levelStyle(lastVal))
+			}
+
+			switch {
+			case lastVal == val || isUnknownBit(lastVal) || isUnknownBit(val):
+			case r.opts.HighColor == "" && r.opts.LowColor == "":
+				p.lineWithShadow(x, y0, x, y1, theme.Wire)
+			default:
+				// blend the transition between the colours of the two
+				// levels, where a high-impedance level already sits at
+				// the midpoint and needs no half of its own
+				mid := y + r.signalHeight/2
+				if y0 != mid {
+					p.lineWithShadow(x, y0, x, mid, r.levelStyle(lastVal))
 				}
-				y1 := y + signalHeight
-				if val == "1" {
-					y1 = y
+				if y1 != mid {
+					p.lineWithShadow(x, mid, x, y1, r.levelStyle(val))
 				}
+			}
+		}
 
-				drawLineWithShadow(canvas, lastX, y0, x, y0, wireStyle)
-				if lastVal != val {
-					drawLineWithShadow(canvas, x, y0, x, y1, wireStyle)
-				}
+		// each run of a value is labelled, even when it returns to the
+		// value of an earlier run
+		if val != lastVal {
+			lastLabel = ""
+		}
+		lastX = x
+		lastVal = val
+	}
+}
+
+// runEnd returns the x coordinate at which a signal first changes from the
+// value it holds at the i-th time, or the end of the trace if it never does.
+func (r *renderer) runEnd(sig string, i int) int {
+	val := r.value(sig, r.times[i])
+	for _, t := range r.times[i+1:] {
+		if r.value(sig, t) != val {
+			return r.timeX(t)
+		}
+	}
+	return r.timeX(r.times[len(r.times)-1])
+}
+
+// levelY returns the y coordinate of the level a wire holding the given
+// value is drawn at, in the row starting at y. A high-impedance level is
+// drawn halfway between the high and low levels.
+func (r *renderer) levelY(val string, y int) int {
+	switch {
+	case val == "1":
+		return y
+	case isTristateBit(val):
+		return y + r.signalHeight/2
+	}
+	return y + r.signalHeight
+}
+
+// drawSegmentDurations labels each stable segment of a signal with its
+// duration in the units of the time labels, centered just above the
+// segment.
+func (r *renderer) drawSegmentDurations(sig string, y int) {
+	start := r.times[0]
+	for i, t := range r.times[1:] {
+		last := i == len(r.times)-2
+		if r.value(sig, t) == r.value(sig, start) && !last {
+			continue
+		}
+		if t > start {
+			x0 := r.timeX(start)
+			x1 := r.timeX(t)
+			r.canvas.Text((x0+x1)/2, y-1, r.durationLabel(t-start), r.theme.Duration)
+		}
+		start = t
+	}
+}
+
+// drawTransactions draws each transaction as a bordered, tinted region over
+// the rows of its signals, with its label in the top left corner. Signals
+// that are not displayed are ignored.
+func (r *renderer) drawTransactions() {
+	for _, tr := range r.opts.Transactions {
+		top, bottom := r.height, 0
+		for i, sig := range r.signals {
+			if slices.Contains(tr.Signals, sig) {
+				top = min(top, r.rowY(i)-r.signalGap/2)
+				bottom = max(bottom, r.rowY(i)+r.signalHeight+r.signalGap/2)
 			}
+		}
+		if bottom <= top {
+			continue
+		}
 
-			lastX = x
-			lastVal = val
+		x0 := r.timeX(tr.Start)
+		x1 := r.timeX(tr.End)
+		r.canvas.Rect(x0, top, x1-x0, bottom-top, r.theme.Transaction)
+		r.canvas.Text(x0+2, top+8, tr.Label, r.theme.TransactionText)
+	}
+}
+
+// drawActivityRuler draws the activity strip beneath the time axis when
+// ShowActivityRuler is set. The transitions of every signal are counted in
+// bins activityBinWidth pixels wide from the axis, and each bin gets a bar
+// whose height is its count relative to the busiest bin.
+func (r *renderer) drawActivityRuler() {
+	if !r.opts.ShowActivityRuler {
+		return
+	}
+
+	bin := func(t uint64) int {
+		return (r.timeX(t) - r.leftMargin) / activityBinWidth
+	}
+	counts := make([]int, bin(r.times[len(r.times)-1])+1)
+	busiest := 0
+	for _, sig := range r.signals {
+		for _, c := range r.changes[sig] {
+			if c.Time > r.times[0] {
+				b := bin(c.Time)
+				counts[b]++
+				busiest = max(busiest, counts[b])
+			}
 		}
-		y += signalHeight + signalGap
+	}
+	if busiest == 0 {
+		return
 	}
 
-	canvas.End()
-	outputBuffer.Flush()
-	return out.Bytes()
-}
\ No newline at end of file
+	r.canvas.Gid("activity-ruler")
+	base := 47 + activityHeight
+	for b, count := range counts {
+		if count == 0 {
+			continue
+		}
+		x := r.leftMargin + b*activityBinWidth
+		h := max(count*activityHeight/busiest, 1)
+		r.canvas.Rect(x+1, r.flipY(base-h, h), activityBinWidth-2, h, r.theme.Activity)
+	}
+	r.canvas.Gend()
+}
+
+// levelStyle returns the style of a scalar wire holding the given value,
+// using HighColor or LowColor when one is set for its level. A
+// high-impedance level is drawn in the Tristate style of the theme.
+func (r *renderer) levelStyle(val string) string {
+	switch {
+	case isTristateBit(val):
+		return r.theme.Tristate
+	case val == "1" && r.opts.HighColor != "":
+		return fmt.Sprintf(wireColorStyle, r.opts.HighColor)
+	case val != "1" && r.opts.LowColor != "":
+		return fmt.Sprintf(wireColorStyle, r.opts.LowColor)
+	}
+	return r.theme.Wire
+}
+
+// isConstantBus reports whether a signal holds the same bus value at every
+// time step.
+func (r *renderer) isConstantBus(sig string) bool {
+	first := r.value(sig, r.times[0])
+	if !r.isBus(sig, first) {
+		return false
+	}
+	for _, t := range r.times[1:] {
+		if r.value(sig, t) != first {
+			return false
+		}
+	}
+	return true
+}
+
+// drawConstantBus draws a bus that never changes as a single band spanning
+// the whole diagram, with one label centered within it.
+func (r *renderer) drawConstantBus(sig string, y int) {
+	x0 := r.timeX(r.times[0])
+	x1 := r.timeX(r.times[len(r.times)-1])
+	yTop := y
+	yBottom := y + (3 * r.signalHeight / 4)
+	val := r.value(sig, r.times[0])
+
+	fillStyle := r.theme.BusFill
+	if color, ok := r.opts.ValueColors[sig][val]; ok {
+		fillStyle = fmt.Sprintf(busColorStyle, color)
+	}
+	r.canvas.Polygon([]int{x0, x1, x1, x0}, []int{yTop, yTop, yBottom, yBottom}, fillStyle)
+
+	p := r.newPen()
+	defer p.flush()
+	p.lineWithShadow(x0, yTop, x1, yTop, r.theme.Bus)
+	p.lineWithShadow(x0, yBottom, x1, yBottom, r.theme.Bus)
+	if !r.thumbnail {
+		r.drawBusLabel((x0+x1)/2, y+(r.signalHeight/2), r.fitLabel(sig, r.valueLabel(sig, val), x1-x0), r.theme.BusValue+" text-anchor:middle;")
+	}
+}
@@ -18,25 +18,30 @@ package waveform
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
 	"testing"
 
+	svg "github.com/ajstarks/svgo"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestDrawSVG_WireSignals(t *testing.T) {
-	vcdData := &VcdData{
-		Sim: map[uint64]map[string]string{
-			0: {"clk": "0", "rst": "1"},
-			1: {"clk": "1", "rst": "1"},
-			2: {"clk": "0", "rst": "0"},
-			3: {"clk": "1", "rst": "0"},
-		},
-		Decl: map[string]string{
-			"!": "clk",
-			"#": "rst",
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"rst"},
 		},
 		Signals: []string{"clk", "rst"},
-	}
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "rst": "1"},
+		1: {"clk": "1", "rst": "1"},
+		2: {"clk": "0", "rst": "0"},
+		3: {"clk": "1", "rst": "0"},
+	})
 
 	svgBytes := DrawSVG(vcdData)
 	svgStr := string(svgBytes)
@@ -47,37 +52,57 @@ func TestDrawSVG_WireSignals(t *testing.T) {
 }
 
 func TestDrawSVG_BusSignal(t *testing.T) {
-	vcdData := &VcdData{
-		Sim: map[uint64]map[string]string{
-			0: {"bus": "b1010"},
-			1: {"bus": "b1010"},
-			2: {"bus": "b1111"},
-			3: {"bus": "b1111"},
-		},
-		Decl: map[string]string{
-			"!": "bus",
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"bus"},
 		},
 		Signals: []string{"bus"},
-	}
+	}, map[uint64]map[string]string{
+		0: {"bus": "b1010"},
+		1: {"bus": "b1010"},
+		2: {"bus": "b1111"},
+		3: {"bus": "b1111"},
+	})
 	svgBytes := DrawSVG(vcdData)
 	svgStr := string(svgBytes)
 
 	assert.Contains(t, svgStr, "<svg")
-	assert.Contains(t, svgStr, "b1010")
+	// the binary prefix of the value is not part of its label
+	assert.Contains(t, svgStr, ">1010</text>")
+	assert.NotContains(t, svgStr, "b1010")
 	assert.NotContains(t, svgStr, "0xAA")
 }
 
+func TestDrawSVG_BusReturnsToEarlierValue(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus"},
+	}, map[uint64]map[string]string{
+		0: {"bus": "1010"},
+		1: {"bus": "1010"},
+		2: {"bus": "1111"},
+		3: {"bus": "1010"},
+		4: {"bus": "1010"},
+		5: {"bus": "1010"},
+	})
+
+	// both runs of 1010 are labelled, although the 1111 between them is
+	// too short to carry a label of its own
+	svgStr := string(DrawSVG(vcdData))
+	assert.Equal(t, 2, strings.Count(svgStr, ">1010</text>"))
+	assert.Contains(t, svgStr, `<text x="151" y="60" style="`+busValueStyle+`" >1010</text>`)
+	assert.Contains(t, svgStr, `<text x="211" y="60" style="`+busValueStyle+`" >1010</text>`)
+}
+
 func TestDrawSVG_ValidSVG(t *testing.T) {
-	vcdData := &VcdData{
-		Sim: map[uint64]map[string]string{
-			0: {"sig": "0"},
-			1: {"sig": "1"},
-		},
-		Decl: map[string]string{
-			"!": "sig",
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"sig"},
 		},
 		Signals: []string{"sig"},
-	}
+	}, map[uint64]map[string]string{
+		0: {"sig": "0"},
+		1: {"sig": "1"},
+	})
 
 	svgBytes := DrawSVG(vcdData)
 
@@ -100,3 +125,1079 @@ func TestDrawSVG_ValidSVG(t *testing.T) {
 		t.Errorf("SVG output does not appear to be valid XML or missing <svg>")
 	}
 }
+
+func TestDrawSVGWithOptions_DualTimeLabels(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"sig"},
+		},
+		Signals: []string{"sig"},
+	}, map[uint64]map[string]string{
+		0: {"sig": "0"},
+		2: {"sig": "1"},
+		5: {"sig": "0"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{DualTimeLabels: true, StepWidth: minLabelSpacing}))
+
+	// each sampled tick carries its ordinal above its absolute time, and
+	// has a column of its own
+	for i, tm := range []int{0, 2, 5} {
+		x := i*minLabelSpacing + leftMargin
+		assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="18" style="%s" >#%d</text>`, x, tickTextStyle, i))
+		assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="30" style="%s" >%d</text>`, x, tickTextStyle, tm))
+	}
+	assert.NotContains(t, string(DrawSVG(vcdData)), ">#1</text>")
+}
+
+func TestDrawSVGWithOptions_ValueColors(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"state"},
+		},
+		Signals: []string{"state"},
+	}, map[uint64]map[string]string{
+		0: {"state": "0001"},
+		1: {"state": "0001"},
+		2: {"state": "0010"},
+		3: {"state": "0010"},
+		4: {"state": "0100"},
+		5: {"state": "0100"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		ValueColors: map[string]map[string]string{
+			"state": {"0001": "red", "0010": "blue"},
+		},
+	}))
+
+	segment := func(x0, x1 int, style string) string {
+		return fmt.Sprintf(`<polygon points="%d,50 %d,50 %d,65 %d,65" style="%s"`, x0, x1, x1, x0, style)
+	}
+	assert.Contains(t, svgStr, segment(150, 170, "fill:red;fill-opacity:0.4"))
+	assert.Contains(t, svgStr, segment(190, 210, "fill:blue;fill-opacity:0.4"))
+	// unmapped values keep the default fill
+	assert.Contains(t, svgStr, segment(230, 250, busFillStyle))
+}
+
+func TestDrawSVG_FinalBusLabelFitsCanvas(t *testing.T) {
+	wide := "0123456789abcdef0123"
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"bus"},
+		},
+		Signals: []string{"bus"},
+	}, map[uint64]map[string]string{
+		0: {"bus": "00"},
+		1: {"bus": "00"},
+		2: {"bus": wide},
+		3: {"bus": wide},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+
+	var width, height int
+	_, err := fmt.Sscanf(svgStr[strings.Index(svgStr, "<svg"):], `<svg width="%d" height="%d"`, &width, &height)
+	if err != nil {
+		t.Fatalf("could not read canvas size: %v", err)
+	}
+
+	labelX := 2*stepWidth + leftMargin + 1
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="60" style="%s" >%s</text>`, labelX, busValueStyle, wide))
+	assert.GreaterOrEqual(t, width, labelX+len(wide)*busCharWidth)
+}
+
+func TestDrawSVG_SignalGroupIDs(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"test clk"},
+			"#": {"test data[7:0]"},
+			"$": {"test/clk"},
+		},
+		Signals: []string{"test clk", "test data[7:0]", "test/clk"},
+	}, map[uint64]map[string]string{
+		0: {"test clk": "0", "test data[7:0]": "00000000", "test/clk": "1"},
+		1: {"test clk": "1", "test data[7:0]": "00000001", "test/clk": "0"},
+	})
+
+	svgBytes := DrawSVG(vcdData)
+
+	// collect the ids of the groups and the label drawn within each
+	groups := map[string]string{}
+	decoder := xml.NewDecoder(bytes.NewReader(svgBytes))
+	var current string
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch el := token.(type) {
+		case xml.StartElement:
+			if el.Name.Local == "g" {
+				for _, attr := range el.Attr {
+					if attr.Name.Local == "id" {
+						current = attr.Value
+					}
+				}
+			}
+			inText = el.Name.Local == "text"
+		case xml.CharData:
+			if inText && current != "" && groups[current] == "" {
+				groups[current] = string(el)
+			}
+		case xml.EndElement:
+			inText = false
+			if el.Name.Local == "g" {
+				current = ""
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"signal-test-clk":       "test clk",
+		"signal-test-data-7-0-": "test data[7:0]",
+		"signal-test-clk-2":     "test/clk",
+	}, groups)
+}
+
+func TestDrawSVGWithOptions_SamplePoints(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"data"},
+		},
+		Signals: []string{"clk", "data"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "data": "0"},
+		1: {"clk": "1", "data": "0"},
+		2: {"clk": "0", "data": "1"},
+		3: {"clk": "1", "data": "1"},
+		4: {"clk": "0", "data": "0"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{SamplePoints: "clk"}))
+
+	// the data row starts at y=80; a low sample sits on the bottom rail and a high one on the top
+	assert.Contains(t, svgStr, fmt.Sprintf(`<circle cx="%d" cy="100" r="3" style="%s" />`, 1*stepWidth+leftMargin, sampleLowStyle))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<circle cx="%d" cy="80" r="3" style="%s" />`, 3*stepWidth+leftMargin, sampleHighStyle))
+	assert.Equal(t, 2, strings.Count(svgStr, "<circle"))
+}
+
+func TestDrawSVGWithOptions_HighlightChanges(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"a"},
+			"#": {"b"},
+			"$": {"c"},
+		},
+		Signals: []string{"a", "b", "c"},
+	}, map[uint64]map[string]string{
+		0: {"a": "0", "b": "0", "c": "0001"},
+		1: {"a": "1", "b": "1", "c": "0001"},
+		2: {"a": "1", "b": "0", "c": "0010"},
+		3: {"a": "0", "b": "0", "c": "0010"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		HighlightChanges: &ChangeHighlight{From: 0, To: 2},
+	}))
+
+	// rows start at y=50 and are 30px apart; b pulses but ends where it started
+	row := func(y int) *regexp.Regexp {
+		return regexp.MustCompile(fmt.Sprintf(`<rect x="0" y="%d" width="\d+" height="30" style="%s" />`,
+			y-signalGap/2, regexp.QuoteMeta(highlightStyle)))
+	}
+	assert.Regexp(t, row(50), svgStr)
+	assert.NotRegexp(t, row(80), svgStr)
+	assert.Regexp(t, row(110), svgStr)
+	assert.Equal(t, 2, strings.Count(svgStr, highlightStyle))
+}
+
+func TestDrawSVGWithOptions_PhaseOrder(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"data"},
+		},
+		Signals: []string{"clk", "data"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "data": "0"},
+		1: {"clk": "1", "data": "1"},
+		2: {"clk": "0", "data": "1"},
+	})
+
+	for _, opts := range []RenderOptions{
+		{},
+		{DualTimeLabels: true, GatedClockDetection: true, SamplePoints: "clk"},
+		{HighlightChanges: &ChangeHighlight{From: 0, To: 2}},
+	} {
+		svgStr := string(DrawSVGWithOptions(vcdData, opts))
+
+		background := strings.Index(svgStr, `style="`+backgroundStyle+`"`)
+		firstGrid := strings.Index(svgStr, `style="`+gridStyle+`"`)
+		lastGrid := strings.LastIndex(svgStr, `style="`+gridStyle+`"`)
+		axis := strings.Index(svgStr, `style="`+axisStyle+`"`)
+		firstTick := strings.Index(svgStr, `style="`+tickStyle+`"`)
+		firstGroup := strings.Index(svgStr, `<g id="signal-clk">`)
+
+		assert.Positive(t, background)
+		assert.Less(t, background, firstGrid)
+		assert.Less(t, lastGrid, axis)
+		assert.Less(t, axis, firstTick)
+		assert.Less(t, firstTick, firstGroup)
+		assert.Less(t, strings.LastIndex(svgStr, `style="`+tickTextStyle+`"`), firstGroup)
+	}
+}
+
+func TestDimensions(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"bus"},
+		},
+		Signals: []string{"bus", "clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "bus": "0001"},
+		1: {"clk": "1", "bus": "0001"},
+		2: {"clk": "0", "bus": "1111111100000000"},
+	})
+
+	for _, opts := range []RenderOptions{{}, {ResampleInterval: 2}} {
+		width, height := Dimensions(vcdData, opts)
+		svgStr := string(DrawSVGWithOptions(vcdData, opts))
+		assert.Contains(t, svgStr, fmt.Sprintf(`<svg width="%d" height="%d"`, width, height))
+	}
+}
+
+func TestDrawSVGWithOptions_DontCareValues(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"sel"},
+			"#": {"addr"},
+		},
+		Signals: []string{"sel", "addr"},
+	}, map[uint64]map[string]string{
+		0: {"sel": "0", "addr": "0001"},
+		1: {"sel": "-", "addr": "????"},
+		2: {"sel": "-", "addr": "????"},
+		3: {"sel": "1", "addr": "0010"},
+		4: {"sel": "1", "addr": "0010"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{DontCareValues: []string{"-", "????"}}))
+
+	band := func(t0, t1 uint64, y int) string {
+		x0 := int(t0)*stepWidth + leftMargin
+		x1 := int(t1)*stepWidth + leftMargin
+		return fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" style="%s" />`, x0, y, x1-x0, signalHeight, dontCareStyle)
+	}
+	// sel is drawn at y=50 and addr at y=80, each don't care from t=1 to t=3
+	assert.Contains(t, svgStr, band(1, 2, 50))
+	assert.Contains(t, svgStr, band(2, 3, 50))
+	assert.Contains(t, svgStr, band(1, 2, 80))
+	assert.Contains(t, svgStr, band(2, 3, 80))
+	assert.Equal(t, 4, strings.Count(svgStr, dontCareStyle))
+	assert.NotContains(t, svgStr, ">????</text>")
+
+	assert.NotContains(t, string(DrawSVG(vcdData)), dontCareStyle)
+}
+
+func TestDrawSVGWithOptions_RealPrecision(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"ratio"},
+		},
+		Signals: []string{"ratio"},
+	}, map[uint64]map[string]string{
+		0: {"ratio": "0"},
+		1: {"ratio": "0.3333333"},
+		2: {"ratio": "0.3333333"},
+		3: {"ratio": "2.5e-1"},
+		4: {"ratio": "2.5e-1"},
+	})
+
+	label := func(svgStr string, text string) bool {
+		return strings.Contains(svgStr, `style="`+busValueStyle+`" >`+text+`</text>`)
+	}
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{RealPrecision: 2}))
+	assert.True(t, label(svgStr, "0.33"))
+	assert.True(t, label(svgStr, "0.25"))
+	assert.NotContains(t, svgStr, "0.3333333")
+
+	svgStr = string(DrawSVG(vcdData))
+	assert.True(t, label(svgStr, "0.333"))
+	assert.True(t, label(svgStr, "0.250"))
+
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{RealPrecision: -1}))
+	assert.True(t, label(svgStr, "0.3333333"))
+	assert.True(t, label(svgStr, "0.25"))
+}
+
+func TestDrawSVGWithOptions_CollapseConstantBuses(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"cfg"},
+			"#": {"bus"},
+		},
+		Signals: []string{"cfg", "bus"},
+	}, map[uint64]map[string]string{
+		0: {"cfg": "10100101", "bus": "0001"},
+		1: {"cfg": "10100101", "bus": "0001"},
+		2: {"cfg": "10100101", "bus": "0010"},
+		3: {"cfg": "10100101", "bus": "0010"},
+		4: {"cfg": "10100101", "bus": "0010"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{CollapseConstantBuses: true}))
+
+	center := (leftMargin + 4*stepWidth + leftMargin) / 2
+	assert.Equal(t, 1, strings.Count(svgStr, ">10100101</text>"))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="60" style="%s text-anchor:middle;" >10100101</text>`, center, busValueStyle))
+	assert.Contains(t, svgStr, `<polygon points="150,50 230,50 230,65 150,65"`)
+
+	// a changing bus is still drawn segment by segment
+	assert.Contains(t, svgStr, ">0001</text>")
+	assert.Contains(t, svgStr, ">0010</text>")
+}
+
+func TestShowSegmentDurations(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "bus"},
+	}, map[uint64]map[string]string{
+		0:  {"a": "0", "bus": "1010"},
+		3:  {"a": "1", "bus": "1010"},
+		8:  {"a": "0", "bus": "0001"},
+		10: {"a": "0", "bus": "0001"},
+	})
+
+	plain := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.NotContains(t, plain, durationStyle)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{ShowSegmentDurations: true}))
+	label := func(x, y int, duration string) string {
+		return fmt.Sprintf(`<text x="%d" y="%d" style="%s" >%s</text>`, x, y, durationStyle, duration)
+	}
+
+	// a is held for 3, 5 and 2 time units, each a single column wide
+	assert.Contains(t, svgStr, label(160, 49, "3"))
+	assert.Contains(t, svgStr, label(180, 49, "5"))
+	assert.Contains(t, svgStr, label(200, 49, "2"))
+
+	// the bus is held for 8 and then 2 time units
+	assert.Contains(t, svgStr, label(170, 79, "8"))
+	assert.Contains(t, svgStr, label(200, 79, "2"))
+	assert.Equal(t, 5, strings.Count(svgStr, durationStyle))
+
+	// durations are scaled to the timescale like the time labels, but are
+	// not shifted by the offset
+	vcdData.Timescale = Timescale{Magnitude: 10, Unit: "ns"}
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{ShowSegmentDurations: true, TimeOffset: 100}))
+	assert.Contains(t, svgStr, label(160, 49, "30ns"))
+	assert.Contains(t, svgStr, label(170, 79, "80ns"))
+	assert.Contains(t, svgStr, `style="`+tickTextStyle+`" >1000ns</text>`)
+}
+
+func TestPartialHexBusLabel(t *testing.T) {
+	assert.Equal(t, "0xA?", busLabel("b1010xxxx", 0))
+	assert.Equal(t, "0xA?", busLabel("1010xxxx", 0))
+	assert.Equal(t, "0x?5", busLabel("z0101", 0))
+	assert.Equal(t, "0x?", busLabel("1z", 0))
+	assert.Equal(t, "xxxx", busLabel("xxxx", 0))
+	assert.Equal(t, "0x1AA", busLabel("110101010", 0))
+
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus"},
+	}, map[uint64]map[string]string{
+		0: {"bus": "1010xxxx"},
+		1: {"bus": "1010xxxx"},
+	})
+	svgStr := string(DrawSVG(vcdData))
+	assert.Contains(t, svgStr, ">0xA?</text>")
+	assert.NotContains(t, svgStr, ">1010xxxx</text>")
+}
+
+func TestMirrorVertical(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b"},
+	}, map[uint64]map[string]string{
+		0: {"a": "0", "b": "1"},
+		1: {"a": "1", "b": "0"},
+	})
+	label := func(y int, name string) string {
+		return fmt.Sprintf(`<text x="10" y="%d" style="%s" >%s</text>`, y, textStyle, name)
+	}
+
+	// the height is 160, so the shared axis sits at the bottom
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{MirrorVertical: true}))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="150" y1="125" x2="150" y2="115" style="%s" />`, tickStyle))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="150" y="140" style="%s" >0</text>`, tickTextStyle))
+
+	// the first signal is closest to the axis, and the next grows upward
+	assert.Contains(t, svgStr, label(100, "a"))
+	assert.Contains(t, svgStr, label(70, "b"))
+
+	normal := string(DrawSVG(vcdData))
+	assert.Contains(t, normal, label(60, "a"))
+	assert.Contains(t, normal, label(90, "b"))
+}
+
+func TestDrawSVGWithOptions_ShowActivityRuler(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b", "c"},
+	}, map[uint64]map[string]string{
+		0: {"a": "0", "b": "0", "c": "00"},
+		1: {"a": "1", "b": "1", "c": "01"},
+		2: {"a": "0", "b": "1", "c": "01"},
+		3: {"a": "0", "b": "1", "c": "01"},
+	})
+
+	plain := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.NotContains(t, plain, `<g id="activity-ruler">`)
+
+	// the bars rise from the foot of the strip beneath the axis, and the
+	// rows are moved down to make room for it
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{ShowActivityRuler: true}))
+	bar := func(x, h int) string {
+		return fmt.Sprintf(`<rect x="%d" y="%d" width="8" height="%d" style="%s" />`, x, 62-h, h, activityStyle)
+	}
+	assert.Contains(t, svgStr, `<g id="activity-ruler">`)
+	assert.Contains(t, svgStr, bar(171, 15))
+	assert.Contains(t, svgStr, bar(191, 5))
+	assert.Equal(t, 2, strings.Count(svgStr, activityStyle))
+	assert.Contains(t, plain, `<g id="signal-a">`+"\n"+`<text x="10" y="60"`)
+	assert.Contains(t, svgStr, `<g id="signal-a">`+"\n"+`<text x="10" y="80"`)
+	plainWidth, plainHeight := Dimensions(vcdData, RenderOptions{})
+	width, height := Dimensions(vcdData, RenderOptions{ShowActivityRuler: true})
+	assert.Equal(t, plainWidth, width)
+	assert.Equal(t, plainHeight+activityHeight+5, height)
+
+	// narrow steps share a bin, which counts the transitions of them all
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(10) {
+		sim[i] = map[string]string{"a": fmt.Sprint(i % 2)}
+	}
+	svgStr = string(DrawSVGWithOptions(withSnapshots(&VcdData{Signals: []string{"a"}}, sim),
+		RenderOptions{ShowActivityRuler: true, StepWidth: 2}))
+	assert.Contains(t, svgStr, bar(151, 12))
+	assert.Contains(t, svgStr, bar(161, 15))
+	assert.Equal(t, 2, strings.Count(svgStr, activityStyle))
+}
+
+func TestDrawSVGWithOptions_RenderDelay(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "q"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "q": "0"},
+		1: {"clk": "1", "q": "1"},
+		2: {"clk": "0", "q": "1"},
+		3: {"clk": "1", "q": "0"},
+	})
+	edge := func(x, y int) string {
+		return fmt.Sprintf(`<line x1="%d" y1="%d" x2="%d" y2="%d" style="%s" />`, x, y+signalHeight, x, y, wireStyle)
+	}
+
+	plain := string(DrawSVG(vcdData))
+	assert.Contains(t, plain, edge(170, 50))
+	assert.Contains(t, plain, edge(170, 80))
+
+	// only q's rising edge moves, and the clock is left in place
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{RenderDelay: map[string]int{"q": 5}}))
+	assert.Contains(t, svgStr, edge(170, 50))
+	assert.Contains(t, svgStr, edge(175, 80))
+	assert.NotContains(t, svgStr, edge(170, 80))
+}
+
+func TestDrawSVGWithOptions_HighLowColors(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0"},
+		1: {"clk": "1"},
+		2: {"clk": "0"},
+	})
+	high := fmt.Sprintf(wireColorStyle, "lime")
+	low := fmt.Sprintf(wireColorStyle, "darkgreen")
+
+	plain := string(DrawSVG(vcdData))
+	assert.NotContains(t, plain, high)
+	assert.NotContains(t, plain, low)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{HighColor: "lime", LowColor: "darkgreen"}))
+	assert.NotContains(t, svgStr, `style="`+wireStyle+`"`)
+
+	// the low and high levels, and each half of the rising edge
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="150" y1="70" x2="170" y2="70" style="%s" />`, low))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="170" y1="50" x2="190" y2="50" style="%s" />`, high))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="170" y1="70" x2="170" y2="60" style="%s" />`, low))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<line x1="170" y1="60" x2="170" y2="50" style="%s" />`, high))
+}
+
+func TestDrawSVGWithOptions_SignalColors(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "rst", "bus"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "rst": "1", "bus": "0001"},
+		1: {"clk": "1", "rst": "0", "bus": "0001"},
+		2: {"clk": "0", "rst": "0", "bus": "0010"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		SignalColors: map[string]string{"clk": "orange", "bus": "magenta"},
+		HighColor:    "lime",
+	}))
+	row := func(sig string) string {
+		start := strings.Index(svgStr, `<g id="signal-`+sig+`">`)
+		return svgStr[start : start+strings.Index(svgStr[start:], "</g>")]
+	}
+
+	// the levels and edges of the coloured wire, in place of HighColor
+	clk := row("clk")
+	wire := fmt.Sprintf(wireColorStyle, "orange")
+	assert.Contains(t, clk, fmt.Sprintf(`<line x1="150" y1="70" x2="170" y2="70" style="%s" />`, wire))
+	assert.Contains(t, clk, fmt.Sprintf(`<line x1="170" y1="70" x2="170" y2="50" style="%s" />`, wire))
+	assert.Contains(t, clk, fmt.Sprintf(`<line x1="170" y1="50" x2="190" y2="50" style="%s" />`, wire))
+	assert.NotContains(t, clk, "lime")
+
+	// the outline of the coloured bus
+	bus := row("bus")
+	assert.Contains(t, bus, fmt.Sprintf(`<line x1="150" y1="110" x2="170" y2="110" style="%s" />`, fmt.Sprintf(busColorLineStyle, "magenta")))
+	assert.NotContains(t, bus, busStyle)
+
+	// other signals keep their colours
+	assert.Contains(t, row("rst"), fmt.Sprintf(wireColorStyle, "lime"))
+	assert.NotContains(t, row("rst"), "orange")
+}
+
+func TestDrawSVGWithOptions_PathRendering(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{
+		Signals: []string{"clk", "bus"},
+	}
+	for i := range uint64(200) {
+		sim[i] = map[string]string{"clk": fmt.Sprint(i % 2), "bus": fmt.Sprintf("%04b", i/8%16)}
+	}
+	vcdData.SetSnapshots(sim)
+
+	// the tooltips are drawn the same either way
+	tooltips := regexp.MustCompile(`<g >\n<title>[^<]*</title>\n<rect [^>]*/>\n</g>\n`)
+	lines := tooltips.ReplaceAllString(string(DrawSVG(vcdData)), "")
+	paths := tooltips.ReplaceAllString(string(DrawSVGWithOptions(vcdData, RenderOptions{PathRendering: true})), "")
+	elements := func(svgStr string) int {
+		signals := svgStr[strings.Index(svgStr, `<g id="signal-clk">`):]
+		return strings.Count(signals, "<line") + strings.Count(signals, "<path")
+	}
+
+	// the clock's wire and shadow, and the bus outline and shadow
+	assert.Equal(t, 4, strings.Count(paths, "<path"))
+	assert.Less(t, elements(paths)*50, elements(lines))
+	assert.Less(t, len(paths)*2, len(lines))
+	assert.Contains(t, paths, `<path d="M150 71L170 71M171 70L171 50M170 51L190 51`)
+	assert.Contains(t, paths, `<path d="M150 70L170 70L170 50L190 50L190 70`)
+	assert.Contains(t, paths, `style="`+wireStyle+`fill:none" />`)
+	assert.Contains(t, paths, `style="`+busStyle+`;fill:none" />`)
+}
+
+func TestDrawSVGWithOptions_Transactions(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "valid", "data"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "valid": "0", "data": "00"},
+		1: {"clk": "1", "valid": "1", "data": "a5"},
+		2: {"clk": "0", "valid": "1", "data": "a5"},
+		3: {"clk": "1", "valid": "0", "data": "00"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		Transactions: []Transaction{
+			{Start: 1, End: 3, Signals: []string{"valid", "data"}, Label: "write"},
+			{Start: 0, End: 1, Signals: []string{"missing"}, Label: "ignored"},
+		},
+	}))
+
+	// the region covers the valid and data rows, from time 1 to time 3
+	assert.Contains(t, svgStr, fmt.Sprintf(`<rect x="170" y="75" width="40" height="60" style="%s" />`, transactionStyle))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="172" y="83" style="%s" >write</text>`, transactionTextStyle))
+	assert.Equal(t, 1, strings.Count(svgStr, transactionStyle))
+	assert.NotContains(t, svgStr, "ignored")
+}
+
+func TestDrawSVGWithOptions_Shadow(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "bus"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "bus": "1010"},
+		1: {"clk": "1", "bus": "1010"},
+		2: {"clk": "0", "bus": "0101"},
+	})
+
+	plain := string(DrawSVG(vcdData))
+	assert.Contains(t, plain, fmt.Sprintf(`<line x1="150" y1="71" x2="170" y2="71" style="%s" />`, shadowStyle))
+
+	disabled := string(DrawSVGWithOptions(vcdData, RenderOptions{Shadow: &ShadowOptions{Disabled: true}}))
+	assert.NotContains(t, disabled, shadowStyle)
+	assert.Contains(t, disabled, fmt.Sprintf(`<line x1="150" y1="70" x2="170" y2="70" style="%s" />`, wireStyle))
+
+	offset := string(DrawSVGWithOptions(vcdData, RenderOptions{Shadow: &ShadowOptions{Offset: 3}}))
+	assert.Contains(t, offset, fmt.Sprintf(`<line x1="150" y1="73" x2="170" y2="73" style="%s" />`, shadowStyle))
+	assert.Contains(t, offset, fmt.Sprintf(`<line x1="173" y1="70" x2="173" y2="50" style="%s" />`, shadowStyle))
+	assert.NotContains(t, offset, `y1="71" x2="170" y2="71"`)
+
+	colored := string(DrawSVGWithOptions(vcdData, RenderOptions{Shadow: &ShadowOptions{Color: "blue", Opacity: 0.8}}))
+	assert.NotContains(t, colored, shadowStyle)
+	assert.Contains(t, colored, `style="stroke:blue;stroke-opacity:0.8;stroke-width:1;"`)
+}
+
+func TestDrawSVGWithOptions_MaxBusLabelChars(t *testing.T) {
+	long := "10101011110011011110111100010010001101000101" // 0xABCDEF12345
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"addr", "short"},
+	}, map[uint64]map[string]string{
+		0: {"addr": long, "short": "0001"},
+		1: {"addr": long, "short": "0001"},
+		2: {"addr": "0", "short": "0001"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{MaxBusLabelChars: 8}))
+	assert.Contains(t, svgStr, `<text x="151" y="60" style="`+busValueStyle+`" >0xAB…345<title>0xABCDEF12345</title>`+"\n</text>")
+	assert.NotContains(t, svgStr, ">0xABCDEF12345</text>")
+	assert.Contains(t, svgStr, ">0001</text>")
+
+	plain := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.Contains(t, plain, ">0xABCDEF12345</text>")
+
+	assert.Equal(t, "abc…yz", abbreviateMiddle("abcdefghijklmnopqrstuvwxyz", 6))
+	assert.Equal(t, "short", abbreviateMiddle("short", 6))
+}
+
+// manySignals builds a trace of n signals over the given number of steps,
+// mixing wires and buses.
+func manySignals(n, steps int) *VcdData {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{}
+	for i := range n {
+		vcdData.Signals = append(vcdData.Signals, fmt.Sprintf("sig%03d", i))
+	}
+	for t := range steps {
+		step := map[string]string{}
+		for i, sig := range vcdData.Signals {
+			if i%3 == 0 {
+				step[sig] = fmt.Sprintf("%08b", (t*(i+1))%256)
+			} else {
+				step[sig] = fmt.Sprint((t / (i%5 + 1)) % 2)
+			}
+		}
+		sim[uint64(t)] = step
+	}
+	vcdData.SetSnapshots(sim)
+	return vcdData
+}
+
+func TestDrawSVGWithOptions_Workers(t *testing.T) {
+	vcdData := manySignals(50, 20)
+	opts := RenderOptions{HighlightChanges: &ChangeHighlight{From: 2, To: 5}, ShowSegmentDurations: true}
+	serial := DrawSVGWithOptions(vcdData, opts)
+
+	for _, workers := range []int{2, 8, 100} {
+		opts.Workers = workers
+		assert.Equal(t, string(serial), string(DrawSVGWithOptions(vcdData, opts)), "workers: %d", workers)
+	}
+}
+
+func BenchmarkDrawSVGWithOptions_Serial(b *testing.B) {
+	vcdData := manySignals(500, 200)
+	for b.Loop() {
+		DrawSVGWithOptions(vcdData, RenderOptions{})
+	}
+}
+
+func BenchmarkDrawSVGWithOptions_Parallel(b *testing.B) {
+	vcdData := manySignals(500, 200)
+	opts := RenderOptions{Workers: runtime.NumCPU()}
+	for b.Loop() {
+		DrawSVGWithOptions(vcdData, opts)
+	}
+}
+
+func TestDrawSVGWithOptions_Dimensions(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus", "clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "bus": "01"},
+		1: {"clk": "1", "bus": "01"},
+		2: {"clk": "0", "bus": "10"},
+	})
+
+	dense := RenderOptions{SignalHeight: 10, SignalGap: 4, StepWidth: 8, LeftMargin: 60}
+	svgStr := string(DrawSVGWithOptions(vcdData, dense))
+	width, height := Dimensions(vcdData, dense)
+	assert.Equal(t, 3*8+60+rightMargin, width)
+	assert.Equal(t, 2*(10+4)+100, height)
+	assert.Contains(t, svgStr, fmt.Sprintf(`<svg width="%d" height="%d"`, width, height))
+
+	// clk is the second row, rising at x=68 from y=74 to y=64
+	assert.Contains(t, svgStr, `<line x1="68" y1="74" x2="68" y2="64" style="`+wireStyle+`" />`)
+	assert.Contains(t, svgStr, `<line x1="68" y1="35" x2="68" y2="45" style="`+tickStyle+`" />`)
+
+	// the narrow steps label only every fifth tick
+	assert.Contains(t, svgStr, `<text x="60" y="30" style="`+tickTextStyle+`" >0</text>`)
+	assert.NotContains(t, svgStr, `>1</text>`)
+
+	// zero and negative dimensions fall back to the defaults
+	fallback := RenderOptions{SignalHeight: -1, SignalGap: 0, StepWidth: -20, LeftMargin: 0}
+	assert.Equal(t, string(DrawSVG(vcdData)), string(DrawSVGWithOptions(vcdData, fallback)))
+}
+
+func TestDrawSVG_UnknownWire(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"rst", "bus"},
+		Widths:  map[string]int{"rst": 1, "bus": 4},
+	}, map[uint64]map[string]string{
+		0: {"rst": "x", "bus": "x"},
+		1: {"rst": "0", "bus": "x"},
+		2: {"rst": "1", "bus": "1010"},
+		3: {"rst": "1", "bus": "1010"},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+	rst := svgStr[strings.Index(svgStr, `<g id="signal-rst">`):strings.Index(svgStr, `<g id="signal-bus">`)]
+
+	// the unknown interval is a band spanning the row, not a bus
+	assert.Contains(t, rst, `<rect x="150" y="50" width="20" height="20" style="`+unknownStyle+`" />`)
+	assert.NotContains(t, rst, "<polygon")
+	assert.NotContains(t, rst, ">x</text>")
+
+	// leaving x draws no edge, the low level then rises normally
+	assert.NotContains(t, rst, `x1="170" y1="70" x2="170"`)
+	assert.Contains(t, rst, `<line x1="170" y1="70" x2="190" y2="70" style="`+wireStyle+`" />`)
+	assert.Contains(t, rst, `<line x1="190" y1="70" x2="190" y2="50" style="`+wireStyle+`" />`)
+
+	// an x on a wider signal is still a bus of unknown bits
+	bus := svgStr[strings.Index(svgStr, `<g id="signal-bus">`):]
+	assert.Contains(t, bus, "<polygon")
+	assert.NotContains(t, bus, unknownStyle)
+}
+
+func TestDrawSVG_UnassignedSignal(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk", "late", "bus"},
+		Widths:  map[string]int{"clk": 1, "late": 1, "bus": 4},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0"},
+		1: {"clk": "1"},
+		2: {"clk": "0", "late": "1", "bus": "0011"},
+		3: {"clk": "1", "late": "0", "bus": "0011"},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+	late := svgStr[strings.Index(svgStr, `<g id="signal-late">`):strings.Index(svgStr, `<g id="signal-bus">`)]
+
+	// the interval before the first assignment, #0 to #2, is a neutral band
+	// rather than a low level, and leaving it draws no edge
+	assert.Contains(t, late, `<rect x="150" y="80" width="20" height="20" style="`+undefinedStyle+`" />`)
+	assert.Contains(t, late, `<rect x="170" y="80" width="20" height="20" style="`+undefinedStyle+`" />`)
+	assert.NotContains(t, late, `y1="100" x2="170"`)
+	assert.NotContains(t, late, `x2="190" y2="100"`)
+	assert.Contains(t, late, `<line x1="190" y1="80" x2="210" y2="80" style="`+wireStyle+`" />`)
+
+	// an unassigned bus is neither filled nor labelled
+	bus := svgStr[strings.Index(svgStr, `<g id="signal-bus">`):]
+	assert.Contains(t, bus, `<rect x="150" y="110" width="20" height="20" style="`+undefinedStyle+`" />`)
+	assert.Equal(t, 1, strings.Count(bus, "<polygon"))
+
+	clk := svgStr[strings.Index(svgStr, `<g id="signal-clk">`):strings.Index(svgStr, `<g id="signal-late">`)]
+	assert.NotContains(t, clk, undefinedStyle)
+}
+
+func TestDrawSVG_TristateWire(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"oe", "bus"},
+		Widths:  map[string]int{"oe": 1, "bus": 4},
+	}, map[uint64]map[string]string{
+		0: {"oe": "1", "bus": "z"},
+		1: {"oe": "z", "bus": "z"},
+		2: {"oe": "Z", "bus": "1010"},
+		3: {"oe": "0", "bus": "1010"},
+		4: {"oe": "0", "bus": "1010"},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+	oe := svgStr[strings.Index(svgStr, `<g id="signal-oe">`):strings.Index(svgStr, `<g id="signal-bus">`)]
+
+	// z is a line halfway between the levels, reached by half an edge
+	assert.Contains(t, oe, `<line x1="170" y1="50" x2="170" y2="60" style="`+wireStyle+`" />`)
+	assert.Contains(t, oe, `<line x1="170" y1="60" x2="190" y2="60" style="`+tristateStyle+`" />`)
+	assert.Contains(t, oe, `<line x1="190" y1="60" x2="210" y2="60" style="`+tristateStyle+`" />`)
+	assert.Contains(t, oe, `<line x1="210" y1="60" x2="210" y2="70" style="`+wireStyle+`" />`)
+	assert.NotContains(t, oe, "<polygon")
+
+	// a z on a wider signal is still a bus
+	bus := svgStr[strings.Index(svgStr, `<g id="signal-bus">`):]
+	assert.Contains(t, bus, "<polygon")
+	assert.NotContains(t, bus, tristateStyle)
+
+	// blended transitions draw only the half on the side of the driven level
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{HighColor: "lime", LowColor: "red"}))
+	assert.Contains(t, svgStr, `<line x1="170" y1="50" x2="170" y2="60" style="`+fmt.Sprintf(wireColorStyle, "lime")+`" />`)
+	assert.NotContains(t, svgStr, `<line x1="170" y1="60" x2="170" y2="60"`)
+	assert.Contains(t, svgStr, `<line x1="210" y1="60" x2="210" y2="70" style="`+fmt.Sprintf(wireColorStyle, "red")+`" />`)
+}
+
+// scalarSignals builds a trace of n single-bit signals over the given
+// number of steps, toggling at different rates.
+func scalarSignals(n, steps int) *VcdData {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{}
+	for i := range n {
+		vcdData.Signals = append(vcdData.Signals, fmt.Sprintf("bit%03d", i))
+	}
+	for t := range steps {
+		step := map[string]string{}
+		for i, sig := range vcdData.Signals {
+			step[sig] = fmt.Sprint((t / (i%7 + 1)) % 2)
+		}
+		sim[uint64(t)] = step
+	}
+	vcdData.SetSnapshots(sim)
+	return vcdData
+}
+
+// drawGeneral renders the diagram without the scalar fast path.
+func drawGeneral(vcdData *VcdData, opts RenderOptions) []byte {
+	var out bytes.Buffer
+	r := newRenderer(svg.New(&out), vcdData, opts)
+	r.scalarOnly = false
+	r.draw()
+	return out.Bytes()
+}
+
+func TestDrawSVGWithOptions_ScalarFastPath(t *testing.T) {
+	vcdData := scalarSignals(20, 30)
+	for _, opts := range []RenderOptions{
+		{},
+		{HighColor: "lime", LowColor: "red"},
+		{RenderDelay: map[string]int{"bit003": 5}},
+		{PathRendering: true},
+		{Workers: 4},
+		{SignalColors: map[string]string{"bit002": "orange"}},
+	} {
+		assert.Equal(t, string(drawGeneral(vcdData, opts)), string(DrawSVGWithOptions(vcdData, opts)))
+	}
+
+	// a coloured signal keeps its colour on the fast path
+	assert.True(t, newRenderer(nil, vcdData, RenderOptions{SignalColors: map[string]string{"bit002": "orange"}}).scalarOnly)
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{SignalColors: map[string]string{"bit002": "orange"}}))
+	signal := svgStr[strings.Index(svgStr, `<g id="signal-bit002">`):]
+	signal = signal[:strings.Index(signal, "</g>")]
+	assert.Contains(t, signal, fmt.Sprintf(`style="%s"`, fmt.Sprintf(wireColorStyle, "orange")))
+	assert.NotContains(t, signal, fmt.Sprintf(`style="%s"`, wireStyle))
+
+	assert.True(t, newRenderer(nil, vcdData, RenderOptions{}).scalarOnly)
+	assert.False(t, newRenderer(nil, vcdData, RenderOptions{DontCareValues: []string{"1"}}).scalarOnly)
+	sim := vcdData.Snapshots()
+	sim[3]["bit000"] = "x"
+	vcdData.SetSnapshots(sim)
+	assert.False(t, newRenderer(nil, vcdData, RenderOptions{}).scalarOnly)
+}
+
+func BenchmarkDrawSVG_ScalarGeneral(b *testing.B) {
+	vcdData := scalarSignals(200, 500)
+	for b.Loop() {
+		drawGeneral(vcdData, RenderOptions{})
+	}
+}
+
+func BenchmarkDrawSVG_ScalarFastPath(b *testing.B) {
+	vcdData := scalarSignals(200, 500)
+	for b.Loop() {
+		DrawSVG(vcdData)
+	}
+}
+
+func TestDrawSVG_SparseTimes(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"sig"},
+	}, map[uint64]map[string]string{
+		0:   {"sig": "0"},
+		5:   {"sig": "1"},
+		100: {"sig": "0"},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+
+	// one column per time
+	assert.Contains(t, svgStr, `<svg width="220" height="130"`)
+	assert.Equal(t, 3, strings.Count(svgStr, `style="`+tickStyle+`"`))
+	assert.Equal(t, 2, strings.Count(svgStr, `style="`+gridStyle+`"`))
+	// one column per time, with every other time labelled
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="30" style="%s" >0</text>`, leftMargin, tickTextStyle))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<text x="%d" y="30" style="%s" >100</text>`, 2*stepWidth+leftMargin, tickTextStyle))
+	assert.NotContains(t, svgStr, `style="`+tickTextStyle+`" >5</text>`)
+	assert.Contains(t, svgStr, `<line x1="170" y1="50" x2="190" y2="50" style="`+wireStyle+`" />`)
+
+	// times between columns are placed in proportion between them
+	r := newRenderer(nil, vcdData, RenderOptions{})
+	assert.Equal(t, 158, r.timeX(2))
+	assert.Equal(t, 180, r.timeX(52))
+	assert.Equal(t, 210, r.timeX(101))
+}
+
+func TestDrawSVG_TwoSamplesDrawInitialValue(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "bus"},
+	}, map[uint64]map[string]string{
+		0: {"a": "1", "bus": "0001"},
+		1: {"a": "0", "bus": "0010"},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+
+	// the wire holds its initial high level from t=0
+	assert.Contains(t, svgStr, `<line x1="150" y1="50" x2="170" y2="50" style="`+wireStyle+`" />`)
+
+	// the bus starts level and labelled with its initial value, rather
+	// than crossing straight into the next
+	bus := svgStr[strings.Index(svgStr, `<g id="signal-bus">`):]
+	assert.Contains(t, bus, `<line x1="150" y1="80" x2="170" y2="80" style="`+busStyle+`" />`)
+	assert.Contains(t, bus, `<line x1="150" y1="95" x2="170" y2="95" style="`+busStyle+`" />`)
+	assert.Contains(t, bus, ">0001</text>")
+	assert.NotContains(t, bus, `<line x1="150" y1="80" x2="170" y2="95"`)
+}
+
+func TestDrawSVG_BusChangeAtSecondStep(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus"},
+	}, map[uint64]map[string]string{
+		0: {"bus": "0001"},
+		1: {"bus": "0010"},
+		2: {"bus": "0010"},
+		3: {"bus": "0100"},
+	})
+
+	svgStr := string(DrawSVG(vcdData))
+
+	// the first value is drawn level and labelled, and its change at the
+	// second time is marked by a short crossing at the end of the level
+	assert.Contains(t, svgStr, `<line x1="150" y1="50" x2="170" y2="50" style="`+busStyle+`" />`)
+	assert.Contains(t, svgStr, ">0001</text>")
+	assert.Contains(t, svgStr, `<line x1="166" y1="50" x2="170" y2="65" style="`+busStyle+`" />`)
+	assert.Contains(t, svgStr, `<line x1="166" y1="65" x2="170" y2="50" style="`+busStyle+`" />`)
+
+	// later changes cross over the whole interval before them
+	assert.Contains(t, svgStr, ">0010</text>")
+	assert.Contains(t, svgStr, `<line x1="190" y1="50" x2="210" y2="65" style="`+busStyle+`" />`)
+}
+
+func TestDrawSVGWithOptions_MaxWidth(t *testing.T) {
+	vcdData := scalarSignals(2, 500)
+
+	width, _ := Dimensions(vcdData, RenderOptions{})
+	assert.Greater(t, width, 1000)
+
+	opts := RenderOptions{MaxWidth: 1000}
+	width, _ = Dimensions(vcdData, opts)
+	assert.LessOrEqual(t, width, 1000)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, opts))
+	assert.Contains(t, svgStr, fmt.Sprintf(`<svg width="%d"`, width))
+
+	// every tick is drawn, but only every 50th tick is labelled
+	assert.Equal(t, 500, strings.Count(svgStr, tickStyle))
+	assert.Equal(t, 10, strings.Count(svgStr, tickTextStyle))
+	assert.Contains(t, svgStr, `>50</text>`)
+	assert.NotContains(t, svgStr, `>49</text>`)
+
+	// a diagram that already fits is unchanged
+	small := scalarSignals(2, 10)
+	assert.Equal(t, DrawSVG(small), DrawSVGWithOptions(small, opts))
+}
+
+func TestNiceInterval(t *testing.T) {
+	for n, want := range map[int]int{0: 1, 1: 1, 2: 2, 3: 5, 5: 5, 6: 10, 11: 20, 21: 50, 51: 100} {
+		assert.Equal(t, want, niceInterval(n), "n=%d", n)
+	}
+}
+
+func TestDrawSVGWithOptions_NarrowStepsThinLabels(t *testing.T) {
+	vcdData := scalarSignals(1, 100)
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{StepWidth: 4}))
+	assert.Equal(t, 100, strings.Count(svgStr, `style="`+tickStyle+`"`))
+	assert.Equal(t, 99, strings.Count(svgStr, `style="`+gridStyle+`"`))
+	assert.Equal(t, 10, strings.Count(svgStr, `style="`+tickTextStyle+`"`))
+	assert.Contains(t, svgStr, `>10</text>`)
+	assert.NotContains(t, svgStr, `>5</text>`)
+
+	// the default steps label every other tick
+	assert.Equal(t, 50, strings.Count(string(DrawSVG(vcdData)), `style="`+tickTextStyle+`"`))
+	assert.Equal(t, 100, strings.Count(string(DrawSVGWithOptions(vcdData, RenderOptions{StepWidth: minLabelSpacing})), `style="`+tickTextStyle+`"`))
+}
+
+// failingWriter accepts a number of bytes and then fails every write.
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		n := w.remaining
+		w.remaining = 0
+		return n, errors.New("disk full")
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
+func TestDrawSVGTo(t *testing.T) {
+	vcdData := scalarSignals(3, 20)
+	opts := RenderOptions{BusRadix: RadixHex}
+
+	var out bytes.Buffer
+	assert.NoError(t, DrawSVGTo(&out, vcdData, opts))
+	assert.Equal(t, DrawSVGWithOptions(vcdData, opts), out.Bytes())
+
+	// errors from the writer are returned, even those hit part way through
+	assert.EqualError(t, DrawSVGTo(&failingWriter{}, vcdData, opts), "disk full")
+	assert.EqualError(t, DrawSVGTo(&failingWriter{remaining: 5000}, vcdData, opts), "disk full")
+}
+
+func TestDrawSVGWithOptions_SignalLabels(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"cpu core alu result"},
+			"#": {"cpu core alu valid"},
+		},
+		Signals: []string{"cpu core alu result", "cpu core alu valid"},
+	}, map[uint64]map[string]string{
+		0: {"cpu core alu result": "0011", "cpu core alu valid": "0"},
+		1: {"cpu core alu result": "0011", "cpu core alu valid": "1"},
+		2: {"cpu core alu result": "0101", "cpu core alu valid": "1"},
+	})
+	opts := RenderOptions{
+		SignalLabels: map[string]string{"cpu core alu result": "result"},
+		ValueColors:  map[string]map[string]string{"cpu core alu result": {"0011": "purple"}},
+	}
+
+	svgStr := string(DrawSVGWithOptions(vcdData, opts))
+	assert.Contains(t, svgStr, `style="`+textStyle+`" >result</text>`)
+	assert.NotContains(t, svgStr, ">cpu core alu result</text>")
+	assert.Contains(t, svgStr, `style="`+textStyle+`" >cpu core alu valid</text>`)
+
+	// the remaining options still refer to the signal by its name
+	assert.Contains(t, svgStr, "fill:purple")
+}
+
+func TestDrawSVG_EmptySim(t *testing.T) {
+	vcdData := &VcdData{Signals: []string{"clk", "data"}}
+
+	// a trace without any simulation draws its labels beside an empty axis
+	svgBytes := DrawSVG(vcdData)
+	assert.NoError(t, xml.Unmarshal(svgBytes, new(struct {
+		XMLName xml.Name `xml:"svg"`
+	})))
+	assert.Contains(t, string(svgBytes), ">clk</text>")
+	assert.Contains(t, string(svgBytes), ">data</text>")
+	assert.Contains(t, string(svgBytes), `style="`+tickTextStyle+`" >0</text>`)
+	assert.Empty(t, vcdData.Times)
+}
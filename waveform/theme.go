@@ -0,0 +1,266 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Theme holds the SVG style strings used when rendering a waveform.
+type Theme struct {
+	Background string `json:"background"`
+	Wire       string `json:"wire"`
+	Shadow     string `json:"shadow"`
+	Bus        string `json:"bus"`
+	BusFill    string `json:"busFill"`
+	BusValue   string `json:"busValue"`
+	Text       string `json:"text"`
+	TickText   string `json:"tickText"`
+	Tick       string `json:"tick"`
+	Grid       string `json:"grid"`
+	Axis       string `json:"axis"`
+
+	// Styles for optional annotations. These may be omitted from a theme
+	// file, in which case the default theme's styles are used.
+	Gated      string `json:"gated,omitempty"`
+	EdgeMarker string `json:"edgeMarker,omitempty"`
+	Analog     string `json:"analog,omitempty"`
+	SampleHigh string `json:"sampleHigh,omitempty"`
+	SampleLow  string `json:"sampleLow,omitempty"`
+	SampleBus  string `json:"sampleBus,omitempty"`
+	Highlight  string `json:"highlight,omitempty"`
+	DontCare   string `json:"dontCare,omitempty"`
+	Subtitle   string `json:"subtitle,omitempty"`
+	Duration   string `json:"duration,omitempty"`
+	Activity   string `json:"activity,omitempty"`
+	Direction  string `json:"direction,omitempty"`
+
+	Transaction     string `json:"transaction,omitempty"`
+	TransactionText string `json:"transactionText,omitempty"`
+	Cursor          string `json:"cursor,omitempty"`
+	CursorText      string `json:"cursorText,omitempty"`
+	Comment         string `json:"comment,omitempty"`
+	Sidebar         string `json:"sidebar,omitempty"`
+	Rail            string `json:"rail,omitempty"`
+	RailText        string `json:"railText,omitempty"`
+	RasterHigh      string `json:"rasterHigh,omitempty"`
+	RasterLow       string `json:"rasterLow,omitempty"`
+	RasterUnknown   string `json:"rasterUnknown,omitempty"`
+	Unknown         string `json:"unknown,omitempty"`
+	Undefined       string `json:"undefined,omitempty"`
+	Tristate        string `json:"tristate,omitempty"`
+	Section         string `json:"section,omitempty"`
+	Separator       string `json:"separator,omitempty"`
+}
+
+// DefaultTheme returns the dark theme used when no theme is specified.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Background: backgroundStyle,
+		Wire:       wireStyle,
+		Shadow:     shadowStyle,
+		Bus:        busStyle,
+		BusFill:    busFillStyle,
+		BusValue:   busValueStyle,
+		Text:       textStyle,
+		TickText:   tickTextStyle,
+		Tick:       tickStyle,
+		Grid:       gridStyle,
+		Axis:       axisStyle,
+		Gated:      gatedStyle,
+		EdgeMarker: edgeMarkerStyle,
+		Analog:     analogStyle,
+		SampleHigh: sampleHighStyle,
+		SampleLow:  sampleLowStyle,
+		SampleBus:  sampleBusStyle,
+		Highlight:  highlightStyle,
+		DontCare:   dontCareStyle,
+		Subtitle:   subtitleStyle,
+		Duration:   durationStyle,
+		Activity:   activityStyle,
+		Direction:  directionStyle,
+
+		Transaction:     transactionStyle,
+		TransactionText: transactionTextStyle,
+		Cursor:          cursorStyle,
+		CursorText:      cursorTextStyle,
+		Comment:         commentStyle,
+		Sidebar:         sidebarStyle,
+		Rail:            railStyle,
+		RailText:        railTextStyle,
+		RasterHigh:      rasterHighStyle,
+		RasterLow:       rasterLowStyle,
+		RasterUnknown:   rasterUnknownStyle,
+		Unknown:         unknownStyle,
+		Undefined:       undefinedStyle,
+		Tristate:        tristateStyle,
+		Section:         sectionStyle,
+		Separator:       separatorStyle,
+	}
+}
+
+// withSignalColor returns a copy of the theme drawing wires and bus
+// outlines in the given colour.
+func (t *Theme) withSignalColor(color string) *Theme {
+	colored := *t
+	colored.Wire = fmt.Sprintf(wireColorStyle, color)
+	colored.Bus = fmt.Sprintf(busColorLineStyle, color)
+	return &colored
+}
+
+// LightTheme returns a theme with a white background, dark text and
+// darker wire colours, suited to printed documentation.
+func LightTheme() *Theme {
+	theme := DefaultTheme()
+	theme.Background = "fill:white"
+	theme.Wire = "stroke:#006400;stroke-width:1;"
+	theme.Tristate = "stroke:#b8860b;stroke-width:1;"
+	theme.Shadow = "stroke:rgba(0,0,0,0.15);stroke-width:1;"
+	theme.Bus = "stroke:#00008b;stroke-width:1"
+	theme.BusFill = "fill:#00008b;fill-opacity:0.08"
+	theme.BusValue = "font-size:10px; font-family:monospace; text-anchor:start; fill:black;"
+	theme.Text = "font-family:monospace; font-size:12px; fill:black;"
+	theme.TickText = "font-size:10px; font-family:monospace; text-anchor:middle; fill:#303030;"
+	theme.Tick = "stroke:#808080;stroke-width:1"
+	theme.Grid = "stroke:#e0e0e0;stroke-width:1;stroke-dasharray:1,1"
+	theme.Axis = "stroke:#404040;stroke-width:2"
+	theme.Subtitle = "font-family:monospace; font-size:8px; fill:#606060;"
+	theme.Direction = "font-family:monospace; font-size:10px; text-anchor:middle; fill:#404040;"
+	theme.Comment = "font-family:monospace; font-size:8px; fill:#404040;"
+	theme.Sidebar = "fill:#f4f4f4;stroke:#c0c0c0;stroke-width:1"
+	theme.Section = "font-family:monospace; font-size:12px; font-weight:bold; fill:#202020;"
+	theme.Separator = "stroke:#c0c0c0;stroke-width:1"
+	theme.RasterHigh = "fill:#006400"
+	theme.RasterLow = "fill:#e0f0e0"
+	theme.EdgeMarker = "fill:#303030;fill-opacity:0.6"
+	theme.Activity = "fill:#00008b;fill-opacity:0.4"
+	theme.Analog = "fill:none;stroke:#00008b;stroke-width:1"
+	return theme
+}
+
+// ThemeNames lists the names of the built-in themes accepted by
+// ThemeByName.
+var ThemeNames = []string{"dark", "light"}
+
+// ThemeByName returns the built-in theme with the given name.
+func ThemeByName(name string) (*Theme, error) {
+	switch strings.ToLower(name) {
+	case "dark":
+		return DefaultTheme(), nil
+	case "light":
+		return LightTheme(), nil
+	}
+	return nil, fmt.Errorf("unknown theme %q (expected one of %s)", name, strings.Join(ThemeNames, ", "))
+}
+
+// Validate checks that every required style of the theme is set, and
+// returns an error naming the missing fields if not.
+func (t *Theme) Validate() error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"background", t.Background},
+		{"wire", t.Wire},
+		{"shadow", t.Shadow},
+		{"bus", t.Bus},
+		{"busFill", t.BusFill},
+		{"busValue", t.BusValue},
+		{"text", t.Text},
+		{"tickText", t.TickText},
+		{"tick", t.Tick},
+		{"grid", t.Grid},
+		{"axis", t.Axis},
+	}
+
+	var missing []string
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("theme is missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ParseTheme decodes a theme from JSON, validates it, and fills any omitted
+// optional styles from the default theme.
+func ParseTheme(content []byte) (*Theme, error) {
+	var theme Theme
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&theme); err != nil {
+		return nil, fmt.Errorf("could not decode theme: %w", err)
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, err
+	}
+
+	defaults := DefaultTheme()
+	for _, field := range []struct {
+		value    *string
+		fallback string
+	}{
+		{&theme.Gated, defaults.Gated},
+		{&theme.EdgeMarker, defaults.EdgeMarker},
+		{&theme.Analog, defaults.Analog},
+		{&theme.SampleHigh, defaults.SampleHigh},
+		{&theme.SampleLow, defaults.SampleLow},
+		{&theme.SampleBus, defaults.SampleBus},
+		{&theme.Highlight, defaults.Highlight},
+		{&theme.DontCare, defaults.DontCare},
+		{&theme.Subtitle, defaults.Subtitle},
+		{&theme.Duration, defaults.Duration},
+		{&theme.Activity, defaults.Activity},
+		{&theme.Direction, defaults.Direction},
+		{&theme.Transaction, defaults.Transaction},
+		{&theme.TransactionText, defaults.TransactionText},
+		{&theme.Cursor, defaults.Cursor},
+		{&theme.CursorText, defaults.CursorText},
+		{&theme.Comment, defaults.Comment},
+		{&theme.Sidebar, defaults.Sidebar},
+		{&theme.Rail, defaults.Rail},
+		{&theme.RailText, defaults.RailText},
+		{&theme.RasterHigh, defaults.RasterHigh},
+		{&theme.RasterLow, defaults.RasterLow},
+		{&theme.RasterUnknown, defaults.RasterUnknown},
+		{&theme.Unknown, defaults.Unknown},
+		{&theme.Undefined, defaults.Undefined},
+		{&theme.Tristate, defaults.Tristate},
+		{&theme.Section, defaults.Section},
+		{&theme.Separator, defaults.Separator},
+	} {
+		if *field.value == "" {
+			*field.value = field.fallback
+		}
+	}
+	return &theme, nil
+}
+
+// LoadTheme reads and parses a JSON theme file.
+func LoadTheme(filename string) (*Theme, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read theme file: %w", err)
+	}
+	return ParseTheme(content)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const paletteJSON = `{
+	"background": "fill:#fdf6e3",
+	"wire": "stroke:#859900;stroke-width:1;",
+	"shadow": "stroke:#eee8d5;stroke-width:1;",
+	"bus": "stroke:#268bd2;stroke-width:1",
+	"busFill": "fill:#268bd2;fill-opacity:0.2",
+	"busValue": "font-size:10px; font-family:monospace; fill:#073642;",
+	"text": "font-family:monospace; font-size:12px; fill:#002b36;",
+	"tickText": "font-size:10px; font-family:monospace; text-anchor:middle; fill:#586e75;",
+	"tick": "stroke:#93a1a1;stroke-width:1",
+	"grid": "stroke:#eee8d5;stroke-width:1;stroke-dasharray:1,1",
+	"axis": "stroke:#657b83;stroke-width:2"
+}`
+
+func TestLoadTheme(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "palette.json")
+	if err := os.WriteFile(filename, []byte(paletteJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := LoadTheme(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, gatedStyle, theme.Gated, "optional styles fall back to the default theme")
+
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"clk"},
+			"#": {"bus"},
+		},
+		Signals: []string{"bus", "clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "bus": "0001"},
+		1: {"clk": "1", "bus": "0001"},
+		2: {"clk": "0", "bus": "0010"},
+	})
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{Theme: theme}))
+
+	for _, style := range []string{
+		theme.Background, theme.Wire, theme.Shadow, theme.Bus, theme.BusFill, theme.BusValue,
+		theme.Text, theme.TickText, theme.Tick, theme.Grid, theme.Axis,
+	} {
+		assert.Contains(t, svgStr, `style="`+style+`"`)
+	}
+	assert.NotContains(t, svgStr, backgroundStyle)
+}
+
+func TestParseTheme_Invalid(t *testing.T) {
+	_, err := ParseTheme([]byte(`{"background": "fill:white"}`))
+	assert.ErrorContains(t, err, "missing required fields")
+	assert.ErrorContains(t, err, "wire")
+
+	_, err = ParseTheme([]byte(`{"backgroud": "fill:white"}`))
+	assert.ErrorContains(t, err, "unknown field")
+
+	_, err = LoadTheme("/this/should/not/exist.json")
+	assert.Error(t, err)
+}
+
+func TestLightTheme(t *testing.T) {
+	theme := LightTheme()
+	assert.NoError(t, theme.Validate())
+	assert.Equal(t, "fill:white", theme.Background)
+	assert.NotContains(t, theme.Text, "fill:white")
+	assert.NotEqual(t, DefaultTheme().Wire, theme.Wire)
+	assert.Equal(t, gatedStyle, theme.Gated, "annotations keep the default styles")
+
+	svgStr := string(DrawSVGWithOptions(withSnapshots(&VcdData{
+		Signals: []string{"clk"},
+	}, map[uint64]map[string]string{0: {"clk": "0"}, 1: {"clk": "1"}}), RenderOptions{Theme: theme}))
+	assert.Contains(t, svgStr, `style="fill:white"`)
+	assert.NotContains(t, svgStr, backgroundStyle)
+
+	// the marks drawn over the waves stand out against the white background
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(8) {
+		sim[i] = map[string]string{"clk": fmt.Sprint(i % 2), "level": fmt.Sprintf("%04b", i)}
+	}
+	svgStr = string(DrawSVGWithOptions(withSnapshots(&VcdData{
+		Signals: []string{"clk", "level"},
+	}, sim), RenderOptions{
+		Theme:             theme,
+		MarkClockEdges:    true,
+		ShowActivityRuler: true,
+		AnalogSignals:     map[string]bool{"level": true},
+	}))
+	assert.Contains(t, svgStr, theme.EdgeMarker)
+	assert.NotContains(t, svgStr, edgeMarkerStyle)
+	assert.Contains(t, svgStr, theme.Activity)
+	assert.NotContains(t, svgStr, activityStyle)
+	assert.Contains(t, svgStr, theme.Analog)
+	assert.NotContains(t, svgStr, analogStyle)
+}
+
+func TestThemeByName(t *testing.T) {
+	theme, err := ThemeByName("dark")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTheme(), theme)
+
+	theme, err = ThemeByName("Light")
+	assert.NoError(t, err)
+	assert.Equal(t, LightTheme(), theme)
+
+	_, err = ThemeByName("sepia")
+	assert.EqualError(t, err, `unknown theme "sepia" (expected one of dark, light)`)
+}
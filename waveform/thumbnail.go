@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+
+	svg "github.com/ajstarks/svgo"
+)
+
+const (
+	// ThumbnailWidth and ThumbnailHeight are the size of the preview
+	// produced by DrawThumbnail.
+	ThumbnailWidth  = 240
+	ThumbnailHeight = 80
+)
+
+// DrawThumbnail generates a small SVG preview of the simulation data, of
+// the given width and height, showing the overall shape of the signals
+// without their names, bus values or the time axis. Traces with more time
+// steps than the preview has pixels are resampled to fit. Only the options
+// that affect the data and the colours of the diagram are used.
+func DrawThumbnail(vcdData *VcdData, opts RenderOptions, width, height int) []byte {
+	thumbOpts := RenderOptions{
+		RenameSignals:       opts.RenameSignals,
+		AutoGroupBitSignals: opts.AutoGroupBitSignals,
+		InitialValues:       opts.InitialValues,
+		Theme:               opts.Theme,
+		ValueColors:         opts.ValueColors,
+		DontCareValues:      opts.DontCareValues,
+		HighColor:           opts.HighColor,
+		LowColor:            opts.LowColor,
+		PathRendering:       true,
+	}
+	if times := vcdData.Times; len(times) > 0 && width > 0 {
+		thumbOpts.ResampleInterval = max((times[len(times)-1]+uint64(width)-1)/uint64(width), 1)
+	}
+	vcdData = prepareData(vcdData, thumbOpts)
+
+	var out bytes.Buffer
+	outputBuffer := bufio.NewWriter(&out)
+
+	// only the waveforms are shown, stretched over the whole preview
+	r := newRenderer(svg.New(outputBuffer), vcdData, thumbOpts)
+	r.thumbnail = true
+	r.canvas.Start(width, height,
+		fmt.Sprintf(`viewBox="%d %d %d %d"`, r.leftMargin, 40, r.width-r.leftMargin, r.height-70),
+		`preserveAspectRatio="none"`)
+	r.drawBackground()
+	r.drawSignals()
+	r.canvas.End()
+
+	outputBuffer.Flush()
+	return out.Bytes()
+}
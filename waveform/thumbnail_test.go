@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawThumbnail(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	vcdData := &VcdData{
+		Signals: []string{"clk", "count"},
+	}
+	for i := range uint64(1000) {
+		sim[i] = map[string]string{"clk": fmt.Sprint(i % 2), "count": fmt.Sprintf("%08b", i/10%256)}
+	}
+	vcdData.SetSnapshots(sim)
+
+	full := string(DrawSVG(vcdData))
+	assert.Contains(t, full, ">clk</text>")
+	assert.Contains(t, full, `style="`+busValueStyle+`"`)
+
+	thumbnail := string(DrawThumbnail(vcdData, RenderOptions{}, ThumbnailWidth, ThumbnailHeight))
+	assert.Contains(t, thumbnail, fmt.Sprintf(`<svg width="%d" height="%d"`, ThumbnailWidth, ThumbnailHeight))
+	assert.Contains(t, thumbnail, `preserveAspectRatio="none"`)
+	assert.NotContains(t, thumbnail, "<text")
+	assert.Contains(t, thumbnail, `<g id="signal-count">`)
+
+	// the trace is resampled, so the preview is a fraction of the size
+	assert.Less(t, len(thumbnail)*10, len(full))
+	assert.Less(t, strings.Count(thumbnail, "<polygon"), ThumbnailWidth)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"math"
+	"strconv"
+)
+
+// RoundingMode selects how a displayed time is rounded.
+type RoundingMode int
+
+const (
+	// RoundNone shows times with as many digits as they need.
+	RoundNone RoundingMode = iota
+	// RoundNearest rounds times to the nearest value with Digits decimal
+	// places.
+	RoundNearest
+	// RoundFloor rounds times down to Digits decimal places.
+	RoundFloor
+	// RoundSignificant rounds times to Digits significant figures.
+	RoundSignificant
+)
+
+// TimeRounding controls how the times shown in labels are rounded.
+type TimeRounding struct {
+	Mode   RoundingMode
+	Digits int
+}
+
+// round applies the rounding to t.
+func (r TimeRounding) round(t float64) float64 {
+	switch r.Mode {
+	case RoundNearest:
+		scale := math.Pow10(r.Digits)
+		return math.Round(t*scale) / scale
+	case RoundFloor:
+		scale := math.Pow10(r.Digits)
+		return math.Floor(t*scale) / scale
+	case RoundSignificant:
+		if t == 0 || r.Digits <= 0 {
+			return t
+		}
+		scale := math.Pow10(r.Digits - 1 - int(math.Floor(math.Log10(math.Abs(t)))))
+		return math.Round(t*scale) / scale
+	}
+	return t
+}
+
+// formatTimeLabel formats a time for display in a label, rounded as
+// requested and followed by its unit.
+func formatTimeLabel(t float64, unit string, rounding TimeRounding) string {
+	return strconv.FormatFloat(rounding.round(t), 'f', -1, 64) + unit
+}
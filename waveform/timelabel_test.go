@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTimeLabel(t *testing.T) {
+	assert.Equal(t, "0.16666ns", formatTimeLabel(0.16666, "ns", TimeRounding{}))
+	assert.Equal(t, "0.17ns", formatTimeLabel(0.16666, "ns", TimeRounding{Mode: RoundSignificant, Digits: 2}))
+	assert.Equal(t, "12000", formatTimeLabel(12345, "", TimeRounding{Mode: RoundSignificant, Digits: 2}))
+	assert.Equal(t, "0.167ns", formatTimeLabel(0.16666, "ns", TimeRounding{Mode: RoundNearest, Digits: 3}))
+	assert.Equal(t, "0.166ns", formatTimeLabel(0.16666, "ns", TimeRounding{Mode: RoundFloor, Digits: 3}))
+	assert.Equal(t, "2", formatTimeLabel(2.4, "", TimeRounding{Mode: RoundNearest}))
+	assert.Equal(t, "7", formatTimeLabel(7, "", TimeRounding{}))
+}
+
+func TestDrawSVGWithOptions_TimeOffset(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"clk"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0"},
+		1: {"clk": "1"},
+		2: {"clk": "0"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{TimeOffset: 1000, Sidebar: &Sidebar{Time: 1}}))
+	assert.Contains(t, svgStr, `<text x="150" y="30" style="`+tickTextStyle+`" >1000</text>`)
+	assert.Contains(t, svgStr, `<text x="190" y="30" style="`+tickTextStyle+`" >1002</text>`)
+	assert.NotContains(t, svgStr, `" >0</text>`)
+	assert.Contains(t, svgStr, ">@ 1001</text>")
+
+	// the offset only changes the labels, not the layout
+	plain := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.Contains(t, plain, `<text x="150" y="30" style="`+tickTextStyle+`" >0</text>`)
+	assert.Equal(t, strings.Count(plain, "<line"), strings.Count(svgStr, "<line"))
+}
+
+const timescaleVcd = `$timescale 10ps $end
+$scope module top $end
+$var wire 1 ! clk $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+#1
+1!
+#2
+0!
+`
+
+func TestDrawSVG_TimescaleLabels(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(timescaleVcd)), "timescale.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, Timescale{Magnitude: 10, Unit: "ps"}, vcdData.Timescale)
+
+	svgStr := string(DrawSVG(vcdData))
+	for _, label := range []string{">0ps<", ">20ps<"} {
+		assert.Contains(t, svgStr, label)
+	}
+	assert.NotContains(t, svgStr, ">10ps<", "labels are at least minLabelSpacing apart")
+
+	// an offset is added before scaling, and traces without a timescale
+	// keep bare times
+	svgStr = string(DrawSVGWithOptions(vcdData, RenderOptions{TimeOffset: 5}))
+	assert.Contains(t, svgStr, ">70ps<")
+	assert.Contains(t, string(cursorReadoutData(vcdData, RenderOptions{})), `"labels":["0ps","10ps","20ps"]`)
+	vcdData.Timescale = Timescale{}
+	assert.Contains(t, string(DrawSVG(vcdData)), ">2<")
+}
+
+func TestDrawSVG_TimeLabelSpacing(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(40) {
+		sim[i*1000] = map[string]string{"clk": fmt.Sprint(i % 2)}
+	}
+	vcdData := withSnapshots(&VcdData{Signals: []string{"clk"}, Timescale: Timescale{Magnitude: 100, Unit: "ns"}}, sim)
+
+	for _, opts := range []RenderOptions{{}, {StepWidth: 30}} {
+		svgStr := string(DrawSVGWithOptions(vcdData, opts))
+		var xs []int
+		for _, m := range regexp.MustCompile(`<text x="(\d+)" y="30" style="` + regexp.QuoteMeta(tickTextStyle) + `" >([^<]*)</text>`).FindAllStringSubmatch(svgStr, -1) {
+			x, _ := strconv.Atoi(m[1])
+			xs = append(xs, x)
+		}
+		assert.Greater(t, len(xs), 1)
+		for i := 1; i < len(xs); i++ {
+			assert.GreaterOrEqual(t, xs[i]-xs[i-1], minLabelSpacing)
+		}
+	}
+
+	// labels wider than minLabelSpacing are spaced further apart still
+	r := newRenderer(nil, vcdData, RenderOptions{StepWidth: 30})
+	width := len(r.timeLabel(vcdData.Times[len(vcdData.Times)-1])) * busCharWidth
+	assert.Greater(t, width, minLabelSpacing)
+	assert.GreaterOrEqual(t, r.labelInterval()*30, width)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import "fmt"
+
+// drawValueTooltips covers each stretch of time a signal holds a value
+// with an invisible area carrying a tooltip, which shows the signal's
+// label, the value and the times it is held between when hovered in a
+// browser. Bus values are shown as labelled, followed by their bits when
+// the label is not binary. Stretches before the signal is first assigned
+// are skipped.
+func (r *renderer) drawValueTooltips(sig string, y int) {
+	changes := r.changes[sig]
+	last := r.times[len(r.times)-1]
+	for i, c := range changes {
+		end := last
+		if i+1 < len(changes) {
+			end = changes[i+1].Time
+		}
+		if c.Value == "" || end <= c.Time {
+			continue
+		}
+		x0 := r.timeX(c.Time)
+		x1 := r.timeX(end)
+		r.canvas.Group()
+		r.canvas.Title(fmt.Sprintf("%s = %s from %s to %s", r.labelName(sig), r.tooltipValue(sig, c.Value),
+			r.timeLabel(c.Time), r.timeLabel(end)))
+		r.canvas.Rect(x0, y, x1-x0, r.signalHeight, tooltipStyle)
+		r.canvas.Gend()
+	}
+}
+
+// tooltipValue returns the text a tooltip shows for a value of the signal.
+// A single bit is shown as it is, as no radix applies to it.
+func (r *renderer) tooltipValue(sig, val string) string {
+	if len(val) == 1 && !r.vcdData.isReal(sig) {
+		return val
+	}
+	bits, radix := normalizeVectorValue(val)
+	label := r.valueLabel(sig, val)
+	if radix != binaryValue || label == bits || r.signalRadix(sig) == RadixHexBin {
+		return label
+	}
+	return label + " (" + bits + ")"
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawSVGWithOptions_ValueTooltips(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals:   []string{"clk", "data"},
+		Widths:    map[string]int{"clk": 1, "data": 4},
+		Timescale: Timescale{Magnitude: 1, Unit: "ns"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0"},
+		1: {"clk": "1", "data": "b1010"},
+		2: {"clk": "0", "data": "b1010"},
+		4: {"clk": "0", "data": "b0011"},
+	})
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BusRadix: RadixHex}))
+	assert.Contains(t, svgStr, "<g >\n<title>clk = 0 from 0ns to 1ns</title>\n"+
+		`<rect x="150" y="50" width="20" height="20" style="`+tooltipStyle+`" />`+"\n</g>")
+	assert.Contains(t, svgStr, "<title>clk = 1 from 1ns to 2ns</title>")
+	assert.Contains(t, svgStr, "<title>clk = 0 from 2ns to 4ns</title>")
+
+	// a bus shows its label and bits, and nothing before it is assigned
+	assert.Contains(t, svgStr, "<title>data = 0xA (1010) from 1ns to 4ns</title>")
+	assert.Equal(t, 1, strings.Count(svgStr, "<title>data = "))
+
+	// thumbnails are too small to hover over
+	assert.NotContains(t, string(DrawThumbnail(vcdData, RenderOptions{}, 200, 50)), "<title>")
+}
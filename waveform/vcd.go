@@ -16,19 +16,123 @@ limitations under the License.
 package waveform
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"maps"
 	"os"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/filmil/go-vcd-parser/vcd"
 )
 
 type VcdData struct {
-	Sim    map[uint64]map[string]string
-	Decl    map[string]string
-	Signals    []string
+	// Times holds every time of the simulation, in ascending order. Each
+	// is drawn as one step of the diagram.
+	Times []uint64
+
+	// Changes holds the changes of value of each signal in time order,
+	// keyed by signal name. A signal holds the value of its latest change
+	// until the next one, which ValueAt looks up.
+	Changes map[string][]Change
+
+
+	// Decl maps each identifier code to the names of the variables
+	// declared with it. A code declared under several names aliases them,
+	// so that a change of its value is recorded for every name.
+	Decl    map[string][]string
+	Signals []string
+
+	// DeclOrder holds the names of the declared variables in the order
+	// their $var commands appear, while Signals is sorted by name.
+	DeclOrder []string
+
+	// Scopes holds the path of scopes each signal was declared in, from
+	// the outermost to the innermost, keyed by signal name.
+	Scopes map[string][]string
+
+	// Widths holds the declared number of bits of each signal, keyed by
+	// signal name.
+	Widths map[string]int
+
+	// Ranges holds the declared [msb:lsb] bit range of bus signals, keyed
+	// by signal name. Signals declared without a range are omitted.
+	Ranges map[string]BitRange
+
+	// Types holds the declared variable type of each signal, e.g. "wire",
+	// "reg" or "integer", keyed by signal name.
+	Types map[string]string
+
+	// Timescale is the unit of the simulation times, or the zero value if
+	// the trace did not declare one.
+	Timescale Timescale
+
+	// Directions holds the port direction of signals that are known to be
+	// ports, keyed by signal name. Standard VCD files do not record port
+	// directions, so this is supplied by the caller.
+	Directions map[string]PortDirection
+
+	// Comments holds the text of the $comment blocks of the file, in the
+	// order they appear.
+	Comments []Comment
+}
+
+// Timescale is the time unit declared by a VCD file, e.g. a timescale of
+// 10ps has a Magnitude of 10 and a Unit of "ps".
+type Timescale struct {
+	Magnitude uint64
+	Unit      string
+}
+
+// String returns the timescale as written in a VCD file, e.g. "10ps".
+func (t Timescale) String() string {
+	return fmt.Sprintf("%d%s", t.Magnitude, t.Unit)
+}
+
+// VarInfo describes how a signal was declared: its variable type, its
+// number of bits and, for buses declared with one, its [msb:lsb] range.
+type VarInfo struct {
+	Type     string
+	Width    int
+	Range    BitRange
+	HasRange bool
+}
+
+// VarInfo returns the declaration of the named signal, or false if the
+// signal was not declared.
+func (v *VcdData) VarInfo(name string) (VarInfo, bool) {
+	width, ok := v.Widths[name]
+	if !ok {
+		return VarInfo{}, false
+	}
+	r, hasRange := v.Ranges[name]
+	return VarInfo{Type: v.Types[name], Width: width, Range: r, HasRange: hasRange}, true
+}
+
+// timeUnitName returns the abbreviation of a parsed time unit.
+func timeUnitName(u *vcd.TimeUnit) string {
+	switch {
+	case u == nil:
+		return ""
+	case u.Second:
+		return "s"
+	case u.MilliSecond:
+		return "ms"
+	case u.MicroSecond:
+		return "us"
+	case u.NanoSecond:
+		return "ns"
+	case u.PicoSecond:
+		return "ps"
+	case u.FemtoSecond:
+		return "fs"
+	}
+	return ""
 }
 
 // ParseVCD parses a VCD  file from the provided bytes.Reader.
@@ -36,12 +140,31 @@ type VcdData struct {
 // It returns a pointer to a VcdData struct containing the parsed simulation data,
 // or an error if parsing fails.
 func ParseVCD(reader *bytes.Reader, name string) (*VcdData, error) {
+	return ParseVCDStream(reader, name)
+}
+
+// ParseVCDStream parses a VCD file read from r, so that callers need not
+// hold the file as a []byte. The name identifies the file in errors.
+//
+// The parser still reads the whole of r before processing it, and builds
+// the syntax tree of the complete file, so memory use grows with the size
+// of the file. Streaming saves only the extra copy that reading the file
+// into a []byte first would hold.
+func ParseVCDStream(r io.Reader, name string) (*VcdData, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+	comments, content := extractComments(content)
+
 	parser := vcd.NewParser[vcd.File]()
-	ast, err := parser.Parse(name, reader)
+	ast, err := parser.ParseBytes(name, content)
 	if err != nil {
 		return nil, fmt.Errorf("parse error: %w", err)
 	}
-	return ProcessVcd(ast), nil
+	vcdData := ProcessVcd(ast)
+	vcdData.Comments = comments
+	return vcdData, nil
 }
 
 // ParseVcdAndGenerateSvg parses a VCD file from the provided bytes.Reader with the given name,
@@ -59,18 +182,35 @@ func ParseVcdAndGenerateSvg(reader *bytes.Reader, name string) ([]byte, error) {
 // parses its contents, and generates an SVG waveform representation.
 // Returns the SVG as a []byte slice, or an error if the file cannot be read or parsed.
 func SvgFromFile(filename string) ([]byte, error) {
+	return SvgFromFileWithOptions(filename, RenderOptions{})
+}
+
+// SvgFromFileWithOptions reads and parses a VCD file like SvgFromFile, and
+// renders it using the provided render options.
+func SvgFromFileWithOptions(filename string, opts RenderOptions) ([]byte, error) {
+	vcdData, err := ParseVCDFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return DrawSVGWithOptions(vcdData, opts), nil
+}
+
+// ParseVCDFile reads and parses the VCD file with the given filename. FST
+// files are detected by their header and read with ParseFSTFile.
+func ParseVCDFile(filename string) (*VcdData, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file: %w", err)
 	}
 	defer file.Close()
 
-	// Read file into memory (for *bytes.Reader compatibility)
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("could not read file: %w", err)
+	// stream the file to the parser, peeking only at the start of it to
+	// detect an FST file
+	reader := bufio.NewReader(file)
+	if header, _ := reader.Peek(9); IsFST(header) {
+		return ParseFSTFile(filename)
 	}
-	return ParseVcdAndGenerateSvg(bytes.NewReader(content), filename)
+	return ParseVCDStream(reader, filename)
 }
 
 // SvgFromBytes parses VCD data provided as a byte slice, and generates
@@ -80,63 +220,580 @@ func SvgFromBytes(content []byte) ([]byte, error) {
 	return ParseVcdAndGenerateSvg(bytes.NewReader(content), "noname.vcd")
 }
 
+// varName returns the name used for a declared variable. Single bit selects
+// such as "d[3]" keep their index so that the bits of a split bus remain
+// distinct signals, while ranged declarations use the bare name.
+func varName(v *vcd.VarT) string {
+	if len(v.Id.Indices) == 1 && v.Id.Indices[0].Index != nil {
+		return v.Id.String()
+	}
+	return v.Id.Name
+}
+
+// varRange returns the [msb:lsb] bit range a variable was declared with.
+func varRange(v *vcd.VarT) (BitRange, bool) {
+	for _, idx := range v.Id.Indices {
+		if idx.MsbIndex != nil && idx.LsbIndex != nil {
+			return BitRange{MSB: *idx.MsbIndex, LSB: *idx.LsbIndex}, true
+		}
+	}
+	return BitRange{}, false
+}
+
 // processVcd processes a parsed VCD AST (Abstract Syntax Tree) and returns a
 // Structure to represent the signal changes over time.
 func ProcessVcd(ast *vcd.File) *VcdData {
+	vcdData := processDeclarations(ast)
+	vcdData.Times = []uint64{0}
+	vcdData.Changes = map[string][]Change{}
+
+	// only the changes of each signal are kept, along with the list of
+	// simulation times; the value a signal holds at any time is that of
+	// its latest change at or before it
+	var s uint64
+	var held map[string]string
+	for _, d := range ast.SimulationCommand {
+		if d.SimulationTime != nil {
+			s = d.SimulationTime.Value()
+			if s > vcdData.Times[len(vcdData.Times)-1] {
+				vcdData.Times = append(vcdData.Times, s)
+			}
+		}
+
+		if d.ValueChange != nil {
+			vcdData.applyValueChange(s, d.ValueChange)
+		}
+
+		// a $dumpall checkpoint assigns the value of every listed variable
+		// at the current time, as do the initial values of $dumpvars
+		if d.Dumpall != nil {
+			for _, vc := range d.Dumpall.ValueChange {
+				vcdData.applyValueChange(s, vc)
+			}
+		}
+		if d.Dumpvars != nil {
+			for _, vc := range d.Dumpvars.ValueChange {
+				vcdData.applyValueChange(s, vc)
+			}
+		}
+
+		// while dumping is suspended every variable is unknown; the values
+		// held when it was suspended are restored once it resumes, updated
+		// by any values listed with the $dumpon
+		if d.Dumpoff != nil {
+			held = vcdData.SnapshotAt(s)
+			for _, names := range vcdData.Decl {
+				for _, name := range names {
+					vcdData.setValue(s, name, "x")
+				}
+			}
+			for _, vc := range d.Dumpoff.ValueChange {
+				vcdData.applyValueChange(s, vc)
+			}
+		}
+		if d.Dumpon != nil {
+			for name, val := range held {
+				vcdData.setValue(s, name, val)
+			}
+			held = nil
+			for _, vc := range d.Dumpon.ValueChange {
+				vcdData.applyValueChange(s, vc)
+			}
+		}
+	}
+
+	// Collect the signal names so they are consistent
+	vcdData.Signals = slices.Sorted(maps.Keys(vcdData.Changes))
+	return vcdData
+}
+
+// applyValueChange records a value change at time s, for every name
+// declared with its code.
+func (v *VcdData) applyValueChange(s uint64, vc *vcd.ValueChangeT) {
+	var code, value string
+	if vc.ScalarValueChange != nil {
+		code, value = vc.ScalarValueChange.GetIdCode(), vc.ScalarValueChange.GetValue()
+	} else if vc.VectorValueChange != nil {
+		code, value = vc.VectorValueChange.GetCode(), vc.VectorValueChange.GetValue()
+	}
+	for _, name := range v.Decl[code] {
+		v.setValue(s, name, value)
+	}
+}
+
+// setValue records that the named signal takes a value at time s.
+func (v *VcdData) setValue(s uint64, name, value string) {
+	v.Changes[name] = appendChange(v.Changes[name], s, value)
+}
+
+// processDeclarations reads the declaration commands of a parsed VCD AST,
+// returning a VcdData holding the signal declarations without any
+// simulation data.
+func processDeclarations(ast *vcd.File) *VcdData {
 	vcdData := VcdData{
-		Sim: map[uint64]map[string]string{
-			0: {},
-		},
-		Decl: map[string]string{},
+		Decl:   map[string][]string{},
+		Scopes: map[string][]string{},
+		Widths: map[string]int{},
+		Ranges: map[string]BitRange{},
+		Types:  map[string]string{},
 	}
 
 	// Determine the signal names from the signal codes
-	// keep track of the scope for the signals
-	scope := []string{""}
+	// keep track of the scope for the signals, the name of a signal is
+	// prefixed with its full scope path
+	scope := []string{}
 	for _, v1 := range ast.DeclarationCommand {
 		if v1.Scope != nil {
-			scope = append(scope, fmt.Sprintf("%s ", v1.Scope.Id))
+			scope = append(scope, v1.Scope.Id)
+		}
+		if v1.Timescale != nil {
+			vcdData.Timescale = Timescale{
+				Magnitude: uint64(v1.Timescale.Number),
+				Unit:      timeUnitName(v1.Timescale.Unit),
+			}
 		}
-		if v1.Upscope != nil {
+		if v1.Upscope != nil && len(scope) > 0 {
 			scope = scope[0 : len(scope)-1]
 		}
 		if v1.Var != nil {
-			vcdData.Decl[v1.Var.Code] = fmt.Sprintf("%s%s", scope[len(scope)-1], v1.Var.Id.Name)
+			name := strings.Join(append(slices.Clone(scope), varName(v1.Var)), " ")
+			if !slices.Contains(vcdData.Decl[v1.Var.Code], name) {
+				vcdData.Decl[v1.Var.Code] = append(vcdData.Decl[v1.Var.Code], name)
+			}
+			if !slices.Contains(vcdData.DeclOrder, name) {
+				vcdData.DeclOrder = append(vcdData.DeclOrder, name)
+			}
+			vcdData.Widths[name] = v1.Var.Size
+			vcdData.Types[name] = v1.Var.VarType
+			if r, ok := varRange(v1.Var); ok {
+				vcdData.Ranges[name] = r
+			}
+			if len(scope) > 0 {
+				vcdData.Scopes[name] = slices.Clone(scope)
+			}
 		}
 	}
+	return &vcdData
+}
 
-	// for each simulation time period keep track of which signals changes
-	// we keep track of every signal at each time period so that it easier
-	// render
-	var s uint64
-	for _, d := range ast.SimulationCommand {
-		if d.SimulationTime != nil {
-			s = d.SimulationTime.Value()
-			_, ok := vcdData.Sim[s]
-			if !ok {
-				vcdData.Sim[s] = maps.Clone(vcdData.Sim[s-1])
+// enddefinitions is the keyword closing the declaration section of a VCD.
+const enddefinitions = "$enddefinitions"
+
+// ParseDeclarationsOnly parses only the declaration section of a VCD file,
+// stopping once $enddefinitions is reached without reading the value
+// changes that follow. The returned VcdData holds the declarations, scopes,
+// widths and timescale, and the sorted names of the declared signals, but
+// no simulation.
+func ParseDeclarationsOnly(reader io.Reader, name string) (*VcdData, error) {
+	vcdData, _, err := readDeclarations(bufio.NewReader(reader), name)
+	return vcdData, err
+}
+
+// readDeclarations parses the declaration section read from lines, like
+// ParseDeclarationsOnly. It also returns the rest of the line holding the
+// final $end, so that the caller can go on to read the value changes.
+func readDeclarations(lines *bufio.Reader, name string) (*VcdData, string, error) {
+	var header strings.Builder
+	var rest string
+	end := -1 // the offset just past $enddefinitions, once it is read
+	for {
+		line, err := lines.ReadString('\n')
+
+		// search only the new line, along with enough of the text before
+		// it to find a keyword split across the two
+		from := header.Len()
+		header.WriteString(line)
+		text := header.String()
+		if end < 0 {
+			start := max(0, from-len(enddefinitions)+1)
+			if i := strings.Index(text[start:], enddefinitions); i >= 0 {
+				end = start + i + len(enddefinitions)
 			}
 		}
 
-		if d.ValueChange != nil {
-			if d.ValueChange.ScalarValueChange != nil {
-				vcdData.Sim[s][vcdData.Decl[d.ValueChange.ScalarValueChange.GetIdCode()]] = d.ValueChange.ScalarValueChange.GetValue()
-			} else if d.ValueChange.VectorValueChange != nil {
-				vcdData.Sim[s][vcdData.Decl[d.ValueChange.VectorValueChange.GetCode()]] = d.ValueChange.VectorValueChange.GetValue()
+		// cut the header after the $end closing $enddefinitions
+		if end >= 0 {
+			start := max(end, from-len("$end")+1)
+			if j := strings.Index(text[start:], "$end"); j >= 0 {
+				rest = text[start+j+len("$end"):]
+				header.Reset()
+				header.WriteString(text[:start+j+len("$end")])
+				break
 			}
 		}
+		if err == io.EOF {
+			return nil, "", fmt.Errorf("parse error: %s has no %s", name, enddefinitions)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("could not read file: %w", err)
+		}
 	}
 
-	// Collect the signal names so they are consistent
-	seen := map[string]bool{}
-	for _, step := range vcdData.Sim {
-		for sig := range step {
-			if !seen[sig] {
+	parser := vcd.NewParser[vcd.File]()
+	ast, err := parser.Parse(name, strings.NewReader(header.String()+"\n"))
+	if err != nil {
+		return nil, "", fmt.Errorf("parse error: %w", err)
+	}
+
+	vcdData := processDeclarations(ast)
+	for _, names := range vcdData.Decl {
+		for _, sig := range names {
+			if !slices.Contains(vcdData.Signals, sig) {
 				vcdData.Signals = append(vcdData.Signals, sig)
-				seen[sig] = true
 			}
 		}
 	}
 	sort.Strings(vcdData.Signals)
-	return &vcdData
+	return vcdData, rest, nil
+}
+
+// Rename returns a copy of the VcdData with signals renamed according to the
+// provided mapping of current name to new name. The Signals, Changes and Decl
+// entries are updated consistently, and names not present in the data are ignored.
+// A signal renamed to the name of another signal takes its place, and of
+// several signals renamed to one name the last in sorted order is kept,
+// listed once where the first of them was.
+func (v *VcdData) Rename(mapping map[string]string) *VcdData {
+	rename := func(name string) string {
+		if newName, ok := mapping[name]; ok {
+			return newName
+		}
+		return name
+	}
+
+	order := slices.Sorted(maps.Keys(mapping))
+	renamed := *v
+	renamed.Changes = renameKeys(v.Changes, mapping, order)
+	renamed.Decl = make(map[string][]string, len(v.Decl))
+	renamed.Signals = make([]string, 0, len(v.Signals))
+	for code, names := range v.Decl {
+		for _, name := range names {
+			if name = rename(name); !slices.Contains(renamed.Decl[code], name) {
+				renamed.Decl[code] = append(renamed.Decl[code], name)
+			}
+		}
+	}
+	for _, sig := range v.Signals {
+		if sig = rename(sig); !slices.Contains(renamed.Signals, sig) {
+			renamed.Signals = append(renamed.Signals, sig)
+		}
+	}
+	renamed.DeclOrder = nil
+	for _, name := range v.DeclOrder {
+		if name = rename(name); !slices.Contains(renamed.DeclOrder, name) {
+			renamed.DeclOrder = append(renamed.DeclOrder, name)
+		}
+	}
+
+	// a renamed signal is shown exactly as named, without its scope
+	renamed.Scopes = maps.Clone(v.Scopes)
+	for name := range mapping {
+		delete(renamed.Scopes, name)
+	}
+	if v.Widths != nil {
+		renamed.Widths = renameKeys(v.Widths, mapping, order)
+	}
+	if v.Ranges != nil {
+		renamed.Ranges = renameKeys(v.Ranges, mapping, order)
+	}
+	if v.Types != nil {
+		renamed.Types = renameKeys(v.Types, mapping, order)
+	}
+	if v.Directions != nil {
+		renamed.Directions = renameKeys(v.Directions, mapping, order)
+	}
+	return &renamed
+}
+
+// renameKeys returns a copy of m with its keys renamed according to the
+// mapping. Where a key is renamed to one already present, the renamed entry
+// wins, and renamed entries are applied in the given order of their current
+// names, so that the result never depends on the order maps are iterated in.
+func renameKeys[V any](m map[string]V, mapping map[string]string, order []string) map[string]V {
+	out := make(map[string]V, len(m))
+	for key, value := range m {
+		if _, ok := mapping[key]; !ok {
+			out[key] = value
+		}
+	}
+	for _, key := range order {
+		if value, ok := m[key]; ok {
+			out[mapping[key]] = value
+		}
+	}
+	return out
+}
+
+// Filter returns a copy of the VcdData holding only the named signals,
+// drawn in the order given rather than sorted. Names that are not present
+// in the data, or that are repeated, are skipped.
+func (v *VcdData) Filter(names []string) *VcdData {
+	present := make(map[string]bool, len(v.Signals))
+	for _, sig := range v.Signals {
+		present[sig] = true
+	}
+
+	keep := map[string]bool{}
+	filtered := *v
+	filtered.Changes = map[string][]Change{}
+	filtered.Decl = map[string][]string{}
+	filtered.Signals = nil
+	for _, name := range names {
+		if present[name] && !keep[name] {
+			filtered.Signals = append(filtered.Signals, name)
+			keep[name] = true
+		}
+	}
+	for sig, changes := range v.Changes {
+		if keep[sig] {
+			filtered.Changes[sig] = changes
+		}
+	}
+	for code, names := range v.Decl {
+		for _, name := range names {
+			if keep[name] {
+				filtered.Decl[code] = append(filtered.Decl[code], name)
+			}
+		}
+	}
+	filtered.DeclOrder = nil
+	for _, name := range v.DeclOrder {
+		if keep[name] {
+			filtered.DeclOrder = append(filtered.DeclOrder, name)
+		}
+	}
+	return &filtered
+}
+
+// bitSignalPattern matches the name of a single bit of a bus, e.g. "data[3]".
+var bitSignalPattern = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// GroupBitSignals returns a copy of the VcdData in which single-bit signals
+// following the "name[index]" convention are combined into one bus signal
+// named after the common prefix. The bus value is built with the highest
+// index as the most significant bit, and bits without a value are shown as
+// "x". The combined signal takes the place of its first bit in Signals, and
+// a bus of consecutive bits is given their range.
+func (v *VcdData) GroupBitSignals() *VcdData {
+	// find the bits that belong to each bus
+	bits := map[string][]int{}
+	for _, sig := range v.Signals {
+		if m := bitSignalPattern.FindStringSubmatch(sig); m != nil {
+			index, _ := strconv.Atoi(m[2])
+			bits[m[1]] = append(bits[m[1]], index)
+		}
+	}
+	for name, indices := range bits {
+		if len(indices) < 2 {
+			delete(bits, name)
+			continue
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(indices)))
+	}
+	if len(bits) == 0 {
+		return v
+	}
+
+	grouped := *v
+	grouped.Changes = make(map[string][]Change, len(v.Changes))
+	grouped.Decl = maps.Clone(v.Decl)
+	grouped.Signals = nil
+	grouped.Scopes = maps.Clone(v.Scopes)
+	grouped.Widths = maps.Clone(v.Widths)
+	if grouped.Widths == nil {
+		grouped.Widths = map[string]int{}
+	}
+	grouped.Ranges = maps.Clone(v.Ranges)
+	if grouped.Ranges == nil {
+		grouped.Ranges = map[string]BitRange{}
+	}
+	busOf := func(sig string) (string, bool) {
+		m := bitSignalPattern.FindStringSubmatch(sig)
+		if m == nil {
+			return "", false
+		}
+		_, ok := bits[m[1]]
+		return m[1], ok
+	}
+
+	added := map[string]bool{}
+	for _, sig := range v.Signals {
+		name, ok := busOf(sig)
+		if !ok {
+			grouped.Signals = append(grouped.Signals, sig)
+		} else if !added[name] {
+			grouped.Signals = append(grouped.Signals, name)
+			added[name] = true
+			grouped.Widths[name] = len(bits[name])
+			if indices := bits[name]; indices[0]-indices[len(indices)-1] == len(indices)-1 {
+				grouped.Ranges[name] = BitRange{MSB: indices[0], LSB: indices[len(indices)-1]}
+			}
+			if path, ok := v.Scopes[sig]; ok {
+				grouped.Scopes[name] = path
+			}
+		}
+	}
+
+	// a bus takes the place of its first declared bit
+	grouped.DeclOrder = nil
+	for _, sig := range v.DeclOrder {
+		if name, ok := busOf(sig); ok {
+			sig = name
+		}
+		if !slices.Contains(grouped.DeclOrder, sig) {
+			grouped.DeclOrder = append(grouped.DeclOrder, sig)
+		}
+	}
+
+	for sig, changes := range v.Changes {
+		if _, ok := busOf(sig); !ok {
+			grouped.Changes[sig] = changes
+		}
+	}
+	for name, indices := range bits {
+		// the bus changes whenever one of its bits does, and starts with
+		// its unassigned bits unknown
+		times := slices.Clone(v.Times[:min(len(v.Times), 1)])
+		for _, index := range indices {
+			for _, c := range v.Changes[fmt.Sprintf("%s[%d]", name, index)] {
+				times = append(times, c.Time)
+			}
+		}
+		slices.Sort(times)
+
+		var changes []Change
+		for _, t := range slices.Compact(times) {
+			var value strings.Builder
+			for _, index := range indices {
+				bit := v.Value(fmt.Sprintf("%s[%d]", name, index), t)
+				if bit == "" {
+					bit = "x"
+				}
+				value.WriteString(bit)
+			}
+			changes = appendChange(changes, t, value.String())
+		}
+		grouped.Changes[name] = changes
+	}
+	return &grouped
+}
+
+// Resample returns a copy of the VcdData in which the only times are 0,
+// interval, 2*interval... up to the last simulation time. Each signal takes
+// at each of them the value in effect at that point, carried forward from
+// its most recent change at or before it. An interval of zero returns the
+// data unchanged.
+func (v *VcdData) Resample(interval uint64) *VcdData {
+	if interval == 0 || len(v.Times) == 0 {
+		return v
+	}
+
+	resampled := *v
+	resampled.Times = nil
+	for t := uint64(0); t <= v.Times[len(v.Times)-1]; t += interval {
+		resampled.Times = append(resampled.Times, t)
+	}
+	resampled.Changes = make(map[string][]Change, len(v.Changes))
+	for sig, changes := range v.Changes {
+		var sampled []Change
+		for _, t := range resampled.Times {
+			val, _ := ValueAt(changes, t)
+			sampled = appendChange(sampled, t, val)
+		}
+		if len(sampled) > 0 {
+			resampled.Changes[sig] = sampled
+		}
+	}
+	resampled.Decl = maps.Clone(v.Decl)
+	resampled.Signals = slices.Clone(v.Signals)
+	return &resampled
+}
+
+// InitialValueMode selects the value a signal holds before its first
+// change in the trace.
+type InitialValueMode int
+
+const (
+	// InitialAbsent leaves a signal without a value until its first change,
+	// as parsed from the VCD file.
+	InitialAbsent InitialValueMode = iota
+	// InitialUnknown treats a signal as unknown ("x") until its first change.
+	InitialUnknown
+	// InitialZero initializes a signal to zero until its first change.
+	InitialZero
+)
+
+// SeedInitialValues returns a copy of the VcdData in which every time step
+// before the first change of a signal holds the initial value selected by
+// the mode. Vector signals are given one bit per declared bit of width.
+// InitialAbsent returns the VcdData unchanged.
+func (v *VcdData) SeedInitialValues(mode InitialValueMode) *VcdData {
+	var bit string
+	switch mode {
+	case InitialUnknown:
+		bit = "x"
+	case InitialZero:
+		bit = "0"
+	default:
+		return v
+	}
+
+	if len(v.Times) == 0 {
+		return v
+	}
+	seeded := *v
+	seeded.Changes = make(map[string][]Change, len(v.Signals))
+	maps.Copy(seeded.Changes, v.Changes)
+	for _, sig := range v.Signals {
+		// every stretch of time the signal is unassigned takes the value
+		initial := strings.Repeat(bit, max(v.Widths[sig], 1))
+		var changes []Change
+		if _, ok := ValueAt(v.Changes[sig], v.Times[0]); !ok {
+			changes = appendChange(changes, v.Times[0], initial)
+		}
+		for _, c := range v.Changes[sig] {
+			if c.Value == "" {
+				c.Value = initial
+			}
+			changes = appendChange(changes, c.Time, c.Value)
+		}
+		seeded.Changes[sig] = changes
+	}
+	return &seeded
+}
+
+// ScopeDisplayMode selects how much of a signal's scope path is shown in
+// its label.
+type ScopeDisplayMode int
+
+const (
+	// ScopeInnermost shows the innermost scope the signal was declared in
+	// followed by its name, e.g. "cpu pc" for top.cpu.pc.
+	ScopeInnermost ScopeDisplayMode = iota
+	// ScopeFull shows the whole scope path of the signal.
+	ScopeFull
+	// ScopeLeafOnly shows only the name of the signal.
+	ScopeLeafOnly
+	// ScopeLastN shows the innermost N scopes of the signal's path.
+	ScopeLastN
+)
+
+// DisplayName returns the label of a signal with its scope path shortened
+// according to the display mode. The depth is only used by ScopeLastN.
+// Signals without a recorded scope are returned unchanged.
+func (v *VcdData) DisplayName(sig string, mode ScopeDisplayMode, depth int) string {
+	path, ok := v.Scopes[sig]
+	if !ok || mode == ScopeFull {
+		return sig
+	}
+	leaf := strings.TrimPrefix(sig, strings.Join(path, " ")+" ")
+
+	switch mode {
+	case ScopeInnermost:
+		depth = 1
+	case ScopeLeafOnly:
+		depth = 0
+	}
+	depth = min(max(depth, 0), len(path))
+	return strings.Join(append(slices.Clone(path[len(path)-depth:]), leaf), " ")
 }
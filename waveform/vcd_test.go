@@ -16,9 +16,12 @@ limitations under the License.
 package waveform
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/filmil/go-vcd-parser/vcd"
 	"github.com/stretchr/testify/assert"
@@ -56,9 +59,10 @@ func TestProcessVcd(t *testing.T) {
 	vcdData := ProcessVcd(ast)
 
 	assert.Len(t, vcdData.Signals, 2)
-	assert.Len(t, vcdData.Sim, 3)
+	assert.Len(t, vcdData.Times, 3)
 	assert.Contains(t, vcdData.Signals, "test clk")
 	assert.Contains(t, vcdData.Signals, "test rst")
+	assert.Equal(t, Timescale{Magnitude: 1, Unit: "ns"}, vcdData.Timescale)
 }
 
 func TestSvgFromBytes_Valid(t *testing.T) {
@@ -72,6 +76,35 @@ func TestSvgFromBytes_Valid(t *testing.T) {
 	assert.Contains(t, string(svg), "rst")
 }
 
+func TestSvgFromBytes_Deterministic(t *testing.T) {
+	for _, content := range []string{simpleVcd, bitSignalsVcd, deepScopeVcd, dumpallVcd} {
+		first, err := SvgFromBytes([]byte(content))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for range 20 {
+			svg, err := SvgFromBytes([]byte(content))
+			assert.NoError(t, err)
+			assert.Equal(t, first, svg)
+		}
+	}
+
+	// options drawn from maps render the same way every time too
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(bitSignalsVcd)), "bits.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts := RenderOptions{
+		AutoGroupBitSignals: true,
+		RenameSignals:       map[string]string{"test clk": "d", "d": "clock"},
+		ValueColors:         map[string]map[string]string{"d": {"0101": "red", "1010": "blue"}},
+	}
+	first := DrawSVGWithOptions(vcdData, opts)
+	for range 20 {
+		assert.Equal(t, first, DrawSVGWithOptions(vcdData, opts))
+	}
+}
+
 func TestSvgFromBytes_Invalid(t *testing.T) {
 	_, err := SvgFromBytes([]byte("$This is not a VCD$"))
 	if err == nil {
@@ -105,3 +138,496 @@ func TestSvgFromFile_Valid(t *testing.T) {
 
 	assert.Contains(t, string(svg), "<svg")
 }
+
+func TestVcdData_Rename(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"n123"},
+			"#": {"clk"},
+		},
+		Signals: []string{"clk", "n123"},
+	}, map[uint64]map[string]string{
+		0: {"n123": "0", "clk": "0"},
+		1: {"n123": "1", "clk": "1"},
+	})
+
+	renamed := vcdData.Rename(map[string]string{"n123": "data_valid", "missing": "ignored"})
+
+	assert.Equal(t, []string{"clk", "data_valid"}, renamed.Signals)
+	assert.Equal(t, "1", renamed.Value("data_valid", 1))
+	assert.NotContains(t, renamed.SnapshotAt(1), "n123")
+	assert.Equal(t, []string{"data_valid"}, renamed.Decl["!"])
+	assert.Contains(t, vcdData.Signals, "n123", "original data should be left untouched")
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{
+		RenameSignals: map[string]string{"n123": "data_valid"},
+	}))
+	assert.Contains(t, svgStr, ">data_valid</text>")
+	assert.NotContains(t, svgStr, "n123")
+
+	// a signal renamed onto another takes its place, whatever the map order
+	swapped := withSnapshots(&VcdData{}, map[uint64]map[string]string{0: {"a": "0", "b": "1", "c": "x"}})
+	for range 20 {
+		assert.Equal(t, map[string]string{"b": "0", "c": "x"}, swapped.Rename(map[string]string{"a": "b"}).SnapshotAt(0))
+		assert.Equal(t, map[string]string{"a": "1", "b": "0", "c": "x"}, swapped.Rename(map[string]string{"a": "b", "b": "a"}).SnapshotAt(0))
+		assert.Equal(t, map[string]string{"b": "x", "d": "1"}, swapped.Rename(map[string]string{"a": "b", "b": "d", "c": "b"}).SnapshotAt(0))
+	}
+
+	// signals renamed to one name are listed, and drawn, once
+	collided := withSnapshots(&VcdData{
+		Signals:   []string{"a", "b", "c"},
+		DeclOrder: []string{"c", "b", "a"},
+	}, map[uint64]map[string]string{0: {"a": "0", "b": "1", "c": "x"}}).Rename(map[string]string{"a": "data", "c": "data"})
+	assert.Equal(t, []string{"data", "b"}, collided.Signals)
+	assert.Equal(t, []string{"data", "b"}, collided.DeclOrder)
+	assert.Equal(t, "x", collided.Value("data", 0))
+	assert.Equal(t, 1, strings.Count(string(DrawSVG(collided)), ">data</text>"))
+}
+
+const bitSignalsVcd = `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 1 " d[0] $end
+$var wire 1 # d[1] $end
+$var wire 1 $ d[2] $end
+$var wire 1 % d[3] $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+0"
+1#
+0$
+1%
+#1
+1!
+1"
+1#
+1$
+0%
+`
+
+func TestGroupBitSignals(t *testing.T) {
+	parser := vcd.NewParser[vcd.File]()
+	ast, err := parser.Parse("bits", strings.NewReader(bitSignalsVcd))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vcdData := ProcessVcd(ast)
+	assert.Equal(t, []string{"test clk", "test d[0]", "test d[1]", "test d[2]", "test d[3]"}, vcdData.Signals)
+
+	grouped := vcdData.GroupBitSignals()
+	assert.Equal(t, []string{"test clk", "test d"}, grouped.Signals)
+	assert.Equal(t, 4, grouped.Widths["test d"])
+	assert.Equal(t, "1010", grouped.Value("test d", 0))
+	assert.Equal(t, "0111", grouped.Value("test d", 1))
+	assert.Equal(t, "1", grouped.Value("test clk", 1))
+	assert.NotContains(t, grouped.SnapshotAt(1), "test d[0]")
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{AutoGroupBitSignals: true}))
+	assert.Contains(t, svgStr, ">test d</text>")
+	assert.NotContains(t, svgStr, "test d[0]")
+	assert.Equal(t, 2, strings.Count(svgStr, "<g id="))
+}
+
+func TestVcdData_Resample(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl: map[string][]string{
+			"!": {"a"},
+			"#": {"b"},
+		},
+		Signals: []string{"a", "b"},
+	}, map[uint64]map[string]string{
+		0:  {"a": "0", "b": "00"},
+		3:  {"a": "1", "b": "00"},
+		7:  {"a": "0", "b": "01"},
+		8:  {"a": "0", "b": "10"},
+		12: {"a": "1", "b": "10"},
+	})
+
+	resampled := vcdData.Resample(5)
+
+	assert.Equal(t, map[uint64]map[string]string{
+		0:  {"a": "0", "b": "00"},
+		5:  {"a": "1", "b": "00"},
+		10: {"a": "0", "b": "10"},
+	}, resampled.Snapshots())
+	assert.Equal(t, vcdData.Signals, resampled.Signals)
+	assert.Same(t, vcdData, vcdData.Resample(0))
+}
+
+func TestVcdData_SnapshotAt(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"a", "b"},
+	}, map[uint64]map[string]string{
+		0: {"a": "0"},
+		3: {"a": "1", "b": "10"},
+		7: {"a": "0"},
+	})
+
+	assert.Equal(t, map[string]string{"a": "0"}, vcdData.SnapshotAt(2))
+	assert.Equal(t, map[string]string{"a": "1", "b": "10"}, vcdData.SnapshotAt(3))
+	assert.Equal(t, map[string]string{"a": "0", "b": "10"}, vcdData.SnapshotAt(100))
+}
+
+const deepScopeVcd = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! rst $end
+$scope module cpu $end
+$scope module alu $end
+$var wire 1 " result $end
+$upscope $end
+$upscope $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+0"
+#1
+1!
+1"
+`
+
+func TestScopeDisplay(t *testing.T) {
+	parser := vcd.NewParser[vcd.File]()
+	ast, err := parser.Parse("deep", strings.NewReader(deepScopeVcd))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vcdData := ProcessVcd(ast)
+	assert.Equal(t, []string{"top cpu alu result", "top rst"}, vcdData.Signals)
+	assert.Equal(t, []string{"top", "cpu", "alu"}, vcdData.Scopes["top cpu alu result"])
+
+	// by default a signal is labelled with its innermost scope
+	innermost := string(DrawSVGWithOptions(vcdData, RenderOptions{}))
+	assert.Contains(t, innermost, ">alu result</text>")
+	assert.Contains(t, innermost, ">top rst</text>")
+	assert.NotContains(t, innermost, "top cpu")
+
+	full := string(DrawSVGWithOptions(vcdData, RenderOptions{ScopeDisplay: ScopeFull}))
+	assert.Contains(t, full, ">top cpu alu result</text>")
+
+	leaf := string(DrawSVGWithOptions(vcdData, RenderOptions{ScopeDisplay: ScopeLeafOnly}))
+	assert.Contains(t, leaf, ">result</text>")
+	assert.Contains(t, leaf, ">rst</text>")
+	assert.NotContains(t, leaf, "top cpu")
+
+	lastN := string(DrawSVGWithOptions(vcdData, RenderOptions{ScopeDisplay: ScopeLastN, ScopeDepth: 1}))
+	assert.Contains(t, lastN, ">alu result</text>")
+	assert.Contains(t, lastN, ">top rst</text>")
+}
+
+const lateSignalVcd = `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 4 " data $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+#1
+1!
+#2
+0!
+b1010 "
+#3
+1!
+`
+
+func TestVcdData_SeedInitialValues(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(lateSignalVcd)), "late.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// as parsed, the signal is absent until it first changes
+	assert.NotContains(t, vcdData.SnapshotAt(1), "test data")
+	assert.Same(t, vcdData, vcdData.SeedInitialValues(InitialAbsent))
+
+	unknown := vcdData.SeedInitialValues(InitialUnknown)
+	assert.Equal(t, "xxxx", unknown.Value("test data", 0))
+	assert.Equal(t, "xxxx", unknown.Value("test data", 1))
+	assert.Equal(t, "1010", unknown.Value("test data", 2))
+	assert.Equal(t, "0", unknown.Value("test clk", 0))
+
+	zero := vcdData.SeedInitialValues(InitialZero)
+	assert.Equal(t, "0000", zero.Value("test data", 0))
+	assert.Equal(t, "0000", zero.Value("test data", 1))
+	assert.Equal(t, "1010", zero.Value("test data", 3))
+
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{InitialValues: InitialUnknown}))
+	assert.Contains(t, svgStr, ">xxxx</text>")
+}
+
+func TestParseDeclarationsOnly(t *testing.T) {
+	vcdData, err := ParseDeclarationsOnly(strings.NewReader(sliceVcd), "slice.vcd")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"top clk", "top cpu data", "top rst"}, vcdData.Signals)
+	assert.Equal(t, []string{"top cpu data"}, vcdData.Decl["#"])
+	assert.Equal(t, []string{"top", "cpu"}, vcdData.Scopes["top cpu data"])
+	assert.Equal(t, Timescale{Magnitude: 10, Unit: "ps"}, vcdData.Timescale)
+	assert.Nil(t, vcdData.Changes)
+
+	// the value changes are never read, so they need not be valid
+	vcdData, err = ParseDeclarationsOnly(strings.NewReader(simpleVcd[:strings.Index(simpleVcd, "#0")]+"#0 garbage"), "bad.vcd")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test clk", "test rst"}, vcdData.Signals)
+
+	// the $end closing the declarations may follow on a later line
+	header := strings.Replace(simpleVcd[:strings.Index(simpleVcd, "#0")], "$enddefinitions $end", "$enddefinitions\n\n$end", 1)
+	vcdData, err = ParseDeclarationsOnly(strings.NewReader(header+"#0 garbage"), "split.vcd")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test clk", "test rst"}, vcdData.Signals)
+
+	_, err = ParseDeclarationsOnly(strings.NewReader("$timescale 1ns $end\n"), "short.vcd")
+	assert.EqualError(t, err, "parse error: short.vcd has no $enddefinitions")
+}
+
+const dumpallVcd = `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 1 # en $end
+$var wire 4 " data $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+0#
+b0000 "
+#1
+1!
+#2
+$dumpall
+0!
+1#
+b1100 "
+$end
+#3
+1!
+`
+
+func TestProcessVcd_Dumpall(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(dumpallVcd)), "dumpall.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, map[string]string{"test clk": "1", "test en": "0", "test data": "0000"}, vcdData.SnapshotAt(1))
+	assert.Equal(t, map[string]string{"test clk": "0", "test en": "1", "test data": "1100"}, vcdData.SnapshotAt(2))
+	assert.Equal(t, map[string]string{"test clk": "1", "test en": "1", "test data": "1100"}, vcdData.SnapshotAt(3))
+}
+
+const dumpoffVcd = `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 4 " data $end
+$upscope $end
+$enddefinitions $end
+#0
+$dumpvars
+0!
+b0011 "
+$end
+#1
+1!
+#2
+$dumpoff
+x!
+bx "
+$end
+#3
+#4
+$dumpon
+0!
+$end
+#5
+b0101 "
+`
+
+func TestProcessVcd_Dumpoff(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(dumpoffVcd)), "dumpoff.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, map[string]string{"test clk": "0", "test data": "0011"}, vcdData.SnapshotAt(0))
+	assert.Equal(t, map[string]string{"test clk": "x", "test data": "x"}, vcdData.SnapshotAt(2))
+	assert.Equal(t, map[string]string{"test clk": "x", "test data": "x"}, vcdData.SnapshotAt(3))
+	assert.Equal(t, map[string]string{"test clk": "0", "test data": "0011"}, vcdData.SnapshotAt(4))
+	assert.Equal(t, map[string]string{"test clk": "0", "test data": "0101"}, vcdData.SnapshotAt(5))
+}
+
+const aliasVcd = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$scope module cpu $end
+$var wire 1 ! clk $end
+$upscope $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+#5
+1!
+`
+
+func TestProcessVcd_AliasedCodes(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(aliasVcd)), "alias.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// both names declared with the code share its values
+	assert.Equal(t, []string{"top clk", "top cpu clk"}, vcdData.Signals)
+	assert.Equal(t, map[string][]string{"!": {"top clk", "top cpu clk"}}, vcdData.Decl)
+	assert.Equal(t, map[string]string{"top clk": "1", "top cpu clk": "1"}, vcdData.SnapshotAt(5))
+
+	svgStr := string(DrawSVG(vcdData))
+	assert.Contains(t, svgStr, `<g id="signal-top-clk">`)
+	assert.Contains(t, svgStr, `<g id="signal-top-cpu-clk">`)
+}
+
+const varInfoVcd = `$timescale 1ns $end
+$scope module test $end
+$var reg 1 ! clk $end
+$var wire 8 # data [7:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+b00001010 #
+`
+
+func TestVcdData_VarInfo(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(varInfoVcd)), "varinfo.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, ok := vcdData.VarInfo("test data")
+	assert.True(t, ok)
+	assert.Equal(t, VarInfo{Type: "wire", Width: 8, Range: BitRange{MSB: 7, LSB: 0}, HasRange: true}, info)
+
+	info, ok = vcdData.VarInfo("test clk")
+	assert.True(t, ok)
+	assert.Equal(t, VarInfo{Type: "reg", Width: 1}, info)
+
+	_, ok = vcdData.VarInfo("test missing")
+	assert.False(t, ok)
+
+	renamed := vcdData.Rename(map[string]string{"test data": "bus"})
+	info, ok = renamed.VarInfo("bus")
+	assert.True(t, ok)
+	assert.Equal(t, "wire", info.Type)
+	assert.Equal(t, 8, info.Width)
+}
+
+const realVcd = `$timescale 1ns $end
+$scope module test $end
+$var real 64 ! level $end
+$var wire 1 # clk $end
+$upscope $end
+$enddefinitions $end
+#0
+r2.5 !
+0#
+#1
+r10 !
+1#
+#2
+0#
+#3
+R0 !
+1#
+#4
+0#
+#5
+1#
+`
+
+func TestProcessVcd_RealValues(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(realVcd)), "real.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, "2.5", vcdData.Value("test level", 0))
+	assert.Equal(t, "10", vcdData.Value("test level", 1))
+	assert.Equal(t, "0", vcdData.Value("test level", 3))
+	assert.Equal(t, "real", vcdData.Types["test level"])
+
+	// every value is drawn as a number, even those that look like bits
+	svgStr := string(DrawSVGWithOptions(vcdData, RenderOptions{BusRadix: RadixHex}))
+	assert.Contains(t, svgStr, ">2.500</text>")
+	assert.Contains(t, svgStr, ">10.000</text>")
+	assert.Contains(t, svgStr, ">0.000</text>")
+
+	// and written back as real value changes
+	out, err := ToVCD(vcdData, nil, 0, 5)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "$var real 64 \" level $end")
+	assert.Contains(t, string(out), "r10 \"")
+	assert.Contains(t, string(out), "r0 \"")
+}
+
+func TestProcessVcd_SparseTimes(t *testing.T) {
+	content := `$timescale 1ns $end
+$scope module test $end
+$var wire 1 ! clk $end
+$var wire 1 # rst $end
+$upscope $end
+$enddefinitions $end
+#0
+1#
+0!
+#3
+1!
+#10
+0!
+`
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(content)), "sparse.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, []uint64{0, 3, 10}, vcdData.Times)
+	assert.Equal(t, map[string]string{"test clk": "1", "test rst": "1"}, vcdData.SnapshotAt(3))
+	assert.Equal(t, map[string]string{"test clk": "0", "test rst": "1"}, vcdData.SnapshotAt(10))
+}
+
+func TestVcdData_Filter(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Decl:    map[string][]string{"!": {"clk"}, "#": {"data"}, "$": {"rst"}, "%": {"valid"}},
+		Signals: []string{"clk", "data", "rst", "valid"},
+	}, map[uint64]map[string]string{
+		0: {"clk": "0", "data": "0001", "rst": "1", "valid": "0"},
+		1: {"clk": "1", "data": "0010", "rst": "0", "valid": "1"},
+	})
+
+	filtered := vcdData.Filter([]string{"valid", "missing", "clk", "valid"})
+	assert.Equal(t, []string{"valid", "clk"}, filtered.Signals)
+	assert.Equal(t, map[string][]string{"!": {"clk"}, "%": {"valid"}}, filtered.Decl)
+	assert.Equal(t, map[string]string{"clk": "1", "valid": "1"}, filtered.SnapshotAt(1))
+	assert.Len(t, vcdData.Signals, 4, "the original data is unchanged")
+
+	svgStr := string(DrawSVG(filtered))
+	assert.Equal(t, 2, strings.Count(svgStr, `<g id="signal-`))
+	assert.Less(t, strings.Index(svgStr, `<g id="signal-valid">`), strings.Index(svgStr, `<g id="signal-clk">`))
+	assert.NotContains(t, svgStr, `<g id="signal-data">`)
+	assert.NotContains(t, svgStr, `<g id="signal-rst">`)
+}
+
+func TestParseVCDStream(t *testing.T) {
+	expected, err := ParseVCD(bytes.NewReader([]byte(simpleVcd)), "simple.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a reader returning a byte at a time is consumed like the whole file
+	vcdData, err := ParseVCDStream(iotest.OneByteReader(strings.NewReader(simpleVcd)), "simple.vcd")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, vcdData)
+
+	_, err = ParseVCDStream(iotest.ErrReader(io.ErrUnexpectedEOF), "broken.vcd")
+	assert.ErrorContains(t, err, "parse error")
+}
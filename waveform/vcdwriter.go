@@ -0,0 +1,132 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ToVCD reconstructs a VCD file holding the selected signals between the
+// start and end times, inclusive. All signals are written when signals is
+// empty. Each signal is declared with its original type within its
+// original scopes, and its value in effect at the start of the window is
+// written at the start time. The time steps in the window keep their
+// original times.
+func ToVCD(vcdData *VcdData, signals []string, start, end uint64) ([]byte, error) {
+	if end < start {
+		return nil, fmt.Errorf("end time %d is before start time %d", end, start)
+	}
+	if len(signals) == 0 {
+		signals = vcdData.Signals
+	}
+	codes := make(map[string]string, len(signals))
+	for i, sig := range signals {
+		if !slices.Contains(vcdData.Signals, sig) {
+			return nil, fmt.Errorf("unknown signal %q", sig)
+		}
+		codes[sig] = vcdCode(i)
+	}
+
+	var out bytes.Buffer
+	if vcdData.Timescale.Unit != "" {
+		fmt.Fprintf(&out, "$timescale %s $end\n", vcdData.Timescale)
+	}
+
+	// declare the signals, opening and closing scopes between them
+	var scope []string
+	initial := vcdData.SnapshotAt(start)
+	for _, sig := range signals {
+		path := vcdData.Scopes[sig]
+		common := 0
+		for common < len(scope) && common < len(path) && scope[common] == path[common] {
+			common++
+		}
+		for range scope[common:] {
+			out.WriteString("$upscope $end\n")
+		}
+		for _, name := range path[common:] {
+			fmt.Fprintf(&out, "$scope module %s $end\n", name)
+		}
+		scope = path
+
+		leaf := strings.Join(strings.Fields(vcdData.DisplayName(sig, ScopeLeafOnly, 0)), "_")
+		varType := vcdData.Types[sig]
+		if vcdData.isReal(sig) || isRealValue(initial[sig]) {
+			fmt.Fprintf(&out, "$var %s 64 %s %s $end\n", cmp.Or(varType, "real"), codes[sig], leaf)
+		} else {
+			width := max(vcdData.Widths[sig], len(initial[sig]), 1)
+			fmt.Fprintf(&out, "$var %s %d %s %s $end\n", cmp.Or(varType, "wire"), width, codes[sig], leaf)
+		}
+	}
+	for range scope {
+		out.WriteString("$upscope $end\n")
+	}
+	out.WriteString("$enddefinitions $end\n")
+
+	// write the values in effect at the start, then every change after it
+	fmt.Fprintf(&out, "#%d\n", start)
+	last := map[string]string{}
+	for _, sig := range signals {
+		if val, ok := initial[sig]; ok {
+			writeVCDValue(&out, val, codes[sig], vcdData.Widths[sig], vcdData.isReal(sig))
+			last[sig] = val
+		}
+	}
+	for t, step := range vcdData.snapshots() {
+		if t <= start || t > end {
+			continue
+		}
+		fmt.Fprintf(&out, "#%d\n", t)
+		for _, sig := range signals {
+			val, ok := step[sig]
+			if ok && val != last[sig] {
+				writeVCDValue(&out, val, codes[sig], vcdData.Widths[sig], vcdData.isReal(sig))
+				last[sig] = val
+			}
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// vcdCode returns the identifier code of the i-th declared signal, using
+// the printable ASCII characters from '!' to '~'.
+func vcdCode(i int) string {
+	const first, count = '!', '~' - '!' + 1
+	code := []byte{byte(first + i%count)}
+	for i /= count; i > 0; i /= count {
+		i--
+		code = append(code, byte(first+i%count))
+	}
+	return string(code)
+}
+
+// writeVCDValue writes a value change for the signal with the given code,
+// as a scalar, vector or real value change. The values of a real variable
+// are always written as real value changes.
+func writeVCDValue(out *bytes.Buffer, val, code string, width int, realVar bool) {
+	switch {
+	case realVar || isRealValue(val):
+		fmt.Fprintf(out, "r%s %s\n", val, code)
+	case width <= 1 && len(val) == 1:
+		fmt.Fprintf(out, "%s%s\n", val, code)
+	default:
+		fmt.Fprintf(out, "b%s %s\n", val, code)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sliceVcd = `$timescale 10ps $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 1 " rst $end
+$scope module cpu $end
+$var wire 4 # data $end
+$upscope $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+1"
+b0000 #
+#1
+1!
+0"
+#2
+0!
+b1010 #
+#3
+1!
+b0110 #
+`
+
+func TestToVCD_RoundTrip(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(sliceVcd)), "slice.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := ToVCD(vcdData, []string{"top cpu data", "top clk"}, 0, 2)
+	assert.NoError(t, err)
+
+	sliced, err := ParseVCD(bytes.NewReader(out), "sliced.vcd")
+	if err != nil {
+		t.Fatalf("could not parse the sliced VCD: %v\n%s", err, out)
+	}
+	assert.Equal(t, []string{"top clk", "top cpu data"}, sliced.Signals)
+	assert.Equal(t, Timescale{Magnitude: 10, Unit: "ps"}, sliced.Timescale)
+	assert.Equal(t, []string{"top", "cpu"}, sliced.Scopes["top cpu data"])
+	assert.Equal(t, 4, sliced.Widths["top cpu data"])
+	assert.Equal(t, map[uint64]map[string]string{
+		0: {"top clk": "0", "top cpu data": "0000"},
+		1: {"top clk": "1", "top cpu data": "0000"},
+		2: {"top clk": "0", "top cpu data": "1010"},
+	}, sliced.Snapshots())
+}
+
+const typedVcd = `$timescale 1ns $end
+$scope module top $end
+$var reg 1 ! q $end
+$var integer 32 " count $end
+$var parameter 8 # width $end
+$var real 64 $ level $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+b0 "
+b1000 #
+r1.5 $
+#1
+1!
+b1 "
+`
+
+func TestToVCD_KeepsVarTypes(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(typedVcd)), "typed.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := ToVCD(vcdData, nil, 0, 1)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "$var reg 1 ")
+	assert.Contains(t, string(out), "$var integer 32 ")
+	assert.Contains(t, string(out), "$var parameter 8 ")
+	assert.Contains(t, string(out), "$var real 64 ")
+
+	written, err := ParseVCD(bytes.NewReader(out), "written.vcd")
+	if err != nil {
+		t.Fatalf("could not parse the written VCD: %v\n%s", err, out)
+	}
+	assert.Equal(t, vcdData.Types, written.Types)
+}
+
+func TestToVCD_Errors(t *testing.T) {
+	vcdData, err := ParseVCD(bytes.NewReader([]byte(sliceVcd)), "slice.vcd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = ToVCD(vcdData, []string{"top missing"}, 0, 2)
+	assert.EqualError(t, err, `unknown signal "top missing"`)
+	_, err = ToVCD(vcdData, nil, 3, 2)
+	assert.EqualError(t, err, "end time 2 is before start time 3")
+}
+
+func TestVcdCode(t *testing.T) {
+	assert.Equal(t, "!", vcdCode(0))
+	assert.Equal(t, "~", vcdCode(93))
+	assert.Equal(t, "!!", vcdCode(94))
+	assert.Equal(t, `"!`, vcdCode(95))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// waveSignal is a lane of a WaveDrom document. Each character of Wave is
+// one time step of the trace, and Data holds the labels of its bus values
+// in order. Period stretches the lane, so that a clock drawn one cycle per
+// character keeps in step with the other lanes.
+type waveSignal struct {
+	Name   string   `json:"name"`
+	Wave   string   `json:"wave"`
+	Data   []string `json:"data,omitempty"`
+	Period int      `json:"period,omitempty"`
+}
+
+// waveDocument is a WaveDrom (WaveJSON) document.
+type waveDocument struct {
+	Signal []waveSignal `json:"signal"`
+}
+
+// ToWaveJSON converts the simulation data into a WaveJSON document that
+// WaveDrom can render and edit. Each time step of the trace becomes one
+// character of every lane: a clock toggling at every step is drawn as a
+// "p" or "n" clock, other wires as "l" and "h" levels, and buses as data
+// entries labelled as in the SVG. Unknown and high-impedance values are
+// drawn as "x" and "z", and a value repeated from the previous step as ".".
+func ToWaveJSON(vcdData *VcdData) ([]byte, error) {
+	times := vcdData.Times
+	doc := waveDocument{Signal: make([]waveSignal, 0, len(vcdData.Signals))}
+	for _, sig := range vcdData.Signals {
+		doc.Signal = append(doc.Signal, waveLane(vcdData, sig, times))
+	}
+	return json.Marshal(doc)
+}
+
+// WaveJSONFromBytes parses VCD data provided as a byte slice and converts it
+// into a WaveJSON document with ToWaveJSON.
+func WaveJSONFromBytes(content []byte) ([]byte, error) {
+	vcdData, err := ParseVCD(bytes.NewReader(content), "noname.vcd")
+	if err != nil {
+		return nil, err
+	}
+	return ToWaveJSON(vcdData)
+}
+
+// waveLane builds the WaveDrom lane of a signal.
+func waveLane(vcdData *VcdData, sig string, times []uint64) waveSignal {
+	lane := waveSignal{Name: sig}
+
+	// a clock toggling at every step draws a whole cycle per character; a
+	// "p" cycle is high for its first half and an "n" cycle low
+	edges, scalar := signalEdges(vcdData.Changes[sig], times)
+	if scalar && len(edges) >= minClockEdges && len(edges) == len(times)-1 {
+		lane.Wave = "n"
+		if vcdData.Value(sig, times[0]) == "1" {
+			lane.Wave = "p"
+		}
+		lane.Wave += strings.Repeat(".", (len(times)+1)/2-1)
+		lane.Period = 2
+		return lane
+	}
+
+	var wave strings.Builder
+	for i, t := range times {
+		val := vcdData.Value(sig, t)
+		if i > 0 && val == vcdData.Value(sig, times[i-1]) {
+			wave.WriteByte('.')
+			continue
+		}
+		switch {
+		case val == "" || strings.Trim(val, "xX") == "":
+			wave.WriteByte('x')
+		case strings.Trim(val, "zZ") == "":
+			wave.WriteByte('z')
+		case vcdData.isBus(sig, val):
+			wave.WriteByte('=')
+			lane.Data = append(lane.Data, busLabel(val, 0))
+		case val == "1":
+			wave.WriteByte('h')
+		default:
+			wave.WriteByte('l')
+		}
+	}
+	lane.Wave = wave.String()
+	return lane
+}
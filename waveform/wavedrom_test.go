@@ -0,0 +1,103 @@
+/*
+Copyright © 2025 David Ellefsen
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package waveform
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const waveVcd = `$timescale 1ns $end
+$scope module top $end
+$var wire 1 ! clk $end
+$var wire 1 " en $end
+$var wire 4 # data [3:0] $end
+$upscope $end
+$enddefinitions $end
+#0
+0!
+0"
+bxxxx #
+#1
+1!
+#2
+0!
+1"
+b1010 #
+#3
+1!
+#4
+0!
+b1010 #
+#5
+1!
+z"
+b0011 #
+`
+
+func TestWaveJSONFromBytes(t *testing.T) {
+	out, err := WaveJSONFromBytes([]byte(waveVcd))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.JSONEq(t, `{"signal":[
+		{"name":"top clk","wave":"n..","period":2},
+		{"name":"top data","wave":"x.=..=","data":["1010","0011"]},
+		{"name":"top en","wave":"l.h..z"}
+	]}`, string(out))
+
+	_, err = WaveJSONFromBytes([]byte("$This is not a VCD$"))
+	assert.Error(t, err)
+}
+
+func TestToWaveJSON_Levels(t *testing.T) {
+	vcdData := withSnapshots(&VcdData{
+		Signals: []string{"bus", "ready", "missing"},
+		Widths:  map[string]int{"bus": 17},
+	}, map[uint64]map[string]string{
+		0: {"ready": "1", "bus": "00000000000000001"},
+		1: {"ready": "1", "bus": "00000000000000001"},
+		2: {"ready": "0", "bus": "10"},
+	})
+
+	out, err := ToWaveJSON(vcdData)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"signal":[
+		{"name":"bus","wave":"=.=","data":["0x1","10"]},
+		{"name":"ready","wave":"h.l"},
+		{"name":"missing","wave":"x.."}
+	]}`, string(out))
+
+	// the format is available by name
+	rendered, err := RenderFormat("wavejson", vcdData, RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, out, rendered)
+}
+
+func TestToWaveJSON_ClockPolarity(t *testing.T) {
+	sim := map[uint64]map[string]string{}
+	for i := range uint64(6) {
+		sim[i] = map[string]string{"clk": fmt.Sprint(1 - i%2)}
+	}
+	vcdData := withSnapshots(&VcdData{Signals: []string{"clk"}}, sim)
+
+	// a clock starting high is drawn with "p" cycles
+	out, err := ToWaveJSON(vcdData)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"signal":[{"name":"clk","wave":"p..","period":2}]}`, string(out))
+}